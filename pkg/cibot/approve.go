@@ -1,22 +1,11 @@
 package cibot
 
 import (
-	"fmt"
-
 	"gitee.com/openeuler/go-gitee/gitee"
 	"github.com/antihax/optional"
 	"github.com/golang/glog"
 )
 
-const (
-	approvedAddedMessage           = `***approved*** is added in this pull request by: ***@%s***. :wave: `
-	approvedRemovedMessage         = `***approved*** is removed in this pull request by: ***@%s***. :flushed: `
-	approvedAddNoPermissionMessage = `***@%s*** has no permission to add ***approved*** in this pull request. :astonished:
-please contact to the collaborators in this repository.`
-	approvedRemoveNoPermissionMessage = `***@%s*** has no permission to remove ***approved*** in this pull request. :astonished:
-please contact to the collaborators in this repository.`
-)
-
 // AddApprove adds approved label
 func (s *Server) AddApprove(event *gitee.NoteEvent) error {
 	// handle PullRequest
@@ -61,7 +50,7 @@ func (s *Server) AddApprove(event *gitee.NoteEvent) error {
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(approvedAddedMessage, commentAuthor)
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageApprovedAdded, nil)
 				owner := event.Repository.Namespace
 				repo := event.Repository.Name
 				number := event.PullRequest.Number
@@ -79,7 +68,7 @@ func (s *Server) AddApprove(event *gitee.NoteEvent) error {
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(approvedAddNoPermissionMessage, commentAuthor)
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageApprovedAddNoPermission, nil)
 				owner := event.Repository.Namespace
 				repo := event.Repository.Name
 				number := event.PullRequest.Number
@@ -138,7 +127,7 @@ func (s *Server) RemoveApprove(event *gitee.NoteEvent) error {
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(approvedRemovedMessage, commentAuthor)
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageApprovedRemoved, nil)
 				owner := event.Repository.Namespace
 				repo := event.Repository.Name
 				number := event.PullRequest.Number
@@ -151,7 +140,7 @@ func (s *Server) RemoveApprove(event *gitee.NoteEvent) error {
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(approvedRemoveNoPermissionMessage, commentAuthor)
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageApprovedRemoveNoPermission, nil)
 				owner := event.Repository.Namespace
 				repo := event.Repository.Name
 				number := event.PullRequest.Number
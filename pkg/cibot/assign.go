@@ -8,101 +8,185 @@ import (
 	"github.com/golang/glog"
 )
 
-const (
-	issueAssignMessage       = `this issue is assigned to: ***@%s***.`
-	issueCanNotAssignMessage = `this issue can not be assigned to: ***@%s***.
-please try to assign to the repository collaborators.`
-	issueNoNeedAssignMessage = `this issue is already assigned to: ***@%s***.
-please do not assign repeatedly.`
-)
-
-// Assign a collaborator for issue
+// Assign collaborators for issue or pull request
 func (s *Server) Assign(event *gitee.NoteEvent) error {
 	if *event.NoteableType == "Issue" {
 		// handle open
 		if event.Issue.State == "open" {
-			// get basic informations
-			comment := event.Comment.Body
-			owner := event.Repository.Namespace
-			repo := event.Repository.Name
-			issueNumber := event.Issue.Number
-			issueAuthor := event.Issue.User.Login
-			commentAuthor := event.Comment.User.Login
-			glog.Infof("assign started. comment: %s owner: %s repo: %s issueNumber: %s issueAuthor: %s commentAuthor: %s",
-				comment, owner, repo, issueNumber, issueAuthor, commentAuthor)
-
-			// split the assignees and operation to be performed.
-			substrings := strings.Split(comment, "@")
-			// default is comment author
-			assignee := commentAuthor
-			if len(substrings) > 1 {
-				assignee = strings.TrimSpace(substrings[1])
-			}
+			return s.AssignIssue(event)
+		}
+	} else if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			return s.AssignPullRequest(event)
+		}
+	}
+	return nil
+}
 
-			// if issue assignee is the same with assignee
-			issueAssignee := ""
-			if event.Issue.Assignee != nil {
-				issueAssignee = event.Issue.Assignee.Login
-			}
+// AssignIssue assigns collaborators for issue
+// the first one is set as assignee if issue has no assignee, and the others are set as collaborators
+func (s *Server) AssignIssue(event *gitee.NoteEvent) error {
+	// get basic informations
+	comment := event.Comment.Body
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	issueNumber := event.Issue.Number
+	issueAuthor := event.Issue.User.Login
+	commentAuthor := event.Comment.User.Login
+	glog.Infof("assign started. comment: %s owner: %s repo: %s issueNumber: %s issueAuthor: %s commentAuthor: %s",
+		comment, owner, repo, issueNumber, issueAuthor, commentAuthor)
 
-			if issueAssignee != assignee {
-				body := gitee.IssueUpdateParam{}
-				body.Repo = repo
-				body.Assignee = assignee
-				body.AccessToken = s.Config.GiteeToken
-				// build label string
-				var strLabel string
-				for _, l := range event.Issue.Labels {
-					strLabel += l.Name + ","
-				}
-				strLabel = strings.TrimRight(strLabel, ",")
-				if strLabel == "" {
-					strLabel = ","
-				}
-				body.Labels = strLabel
-
-				glog.Infof("invoke api to assign: %s", issueNumber)
-
-				// patch assignee
-				_, response, err := s.GiteeClient.IssuesApi.PatchV5ReposOwnerIssuesNumber(s.Context, owner, issueNumber, body)
-				if err != nil {
-					if response.StatusCode == 403 {
-						glog.Infof("unable to assign with status code %d: %s", response.StatusCode, issueNumber)
-						// add comment
-						body := gitee.IssueCommentPostParam{}
-						body.AccessToken = s.Config.GiteeToken
-						body.Body = fmt.Sprintf(issueCanNotAssignMessage, assignee)
-						_, _, err := s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, issueNumber, body)
-						if err != nil {
-							glog.Errorf("unable to add comment in issue: %v", err)
-						}
-					} else {
-						glog.Errorf("unable to assign: %s err: %v", issueNumber, err)
-						return err
-					}
-				} else {
-					glog.Infof("assign successfully: %v", issueNumber)
-					// add comment
-					body := gitee.IssueCommentPostParam{}
-					body.AccessToken = s.Config.GiteeToken
-					body.Body = fmt.Sprintf(issueAssignMessage, assignee)
-					_, _, err := s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, issueNumber, body)
-					if err != nil {
-						glog.Errorf("unable to add comment in issue: %v", err)
-					}
-				}
+	// current assignee and collaborators
+	issueAssignee := ""
+	if event.Issue.Assignee != nil {
+		issueAssignee = event.Issue.Assignee.Login
+	}
+	listOfCollaborators := make([]string, 0, len(event.Issue.Collaborators))
+	for _, c := range event.Issue.Collaborators {
+		listOfCollaborators = append(listOfCollaborators, c.Login)
+	}
+	listOfCurrent := []string{issueAssignee}
+	listOfCurrent = append(listOfCurrent, listOfCollaborators...)
+
+	listOfAlreadyAssigned, listOfNotCollaborators, listOfAssignees, err := s.checkAssignees(
+		owner, repo, GetLoginsFromComment(RegAssign, comment, commentAuthor), listOfCurrent)
+	if err != nil {
+		return err
+	}
+
+	// patch assignee and collaborators
+	var patchErr error
+	if len(listOfAssignees) > 0 {
+		for _, assignee := range listOfAssignees {
+			if issueAssignee == "" {
+				issueAssignee = assignee
 			} else {
-				glog.Infof("already assign: %v", issueNumber)
-				// add comment
-				body := gitee.IssueCommentPostParam{}
-				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(issueNoNeedAssignMessage, assignee)
-				_, _, err := s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, issueNumber, body)
-				if err != nil {
-					glog.Errorf("unable to add comment in issue: %v", err)
-				}
+				listOfCollaborators = append(listOfCollaborators, assignee)
 			}
 		}
+		glog.Infof("invoke api to assign: %s assignee: %s collaborators: %v", issueNumber, issueAssignee, listOfCollaborators)
+		patchErr = s.PatchIssueAssignees(event, issueAssignee, listOfCollaborators)
+	}
+
+	// add comment
+	return s.AddCommentInNoteEvent(event, s.buildAssignMessage(event, listOfAssignees, listOfAlreadyAssigned, listOfNotCollaborators, patchErr))
+}
+
+// AssignPullRequest assigns collaborators for pull request
+func (s *Server) AssignPullRequest(event *gitee.NoteEvent) error {
+	// get basic params
+	comment := event.Comment.Body
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	prAuthor := event.PullRequest.User.Login
+	prNumber := event.PullRequest.Number
+	commentAuthor := event.Comment.User.Login
+	glog.Infof("assign started. comment: %s prAuthor: %s commentAuthor: %s owner: %s repo: %s number: %d",
+		comment, prAuthor, commentAuthor, owner, repo, prNumber)
+
+	// current assignees
+	listOfPrAssignees := make([]string, 0, len(event.PullRequest.Assignees))
+	for _, assignee := range event.PullRequest.Assignees {
+		listOfPrAssignees = append(listOfPrAssignees, assignee.Login)
+	}
+
+	listOfAlreadyAssigned, listOfNotCollaborators, listOfAssignees, err := s.checkAssignees(
+		owner, repo, GetLoginsFromComment(RegAssign, comment, commentAuthor), listOfPrAssignees)
+	if err != nil {
+		return err
+	}
+
+	// add assignees
+	var addErr error
+	if len(listOfAssignees) > 0 {
+		addErr = s.AddSpecifyAssigneesInPullRequest(owner, repo, prNumber, listOfAssignees)
+		if addErr == nil {
+			addErr = s.AddReviewRequests(owner, repo, prNumber, listOfAssignees)
+		}
+	}
+
+	// add comment
+	return s.AddCommentInNoteEvent(event, s.buildAssignMessage(event, listOfAssignees, listOfAlreadyAssigned, listOfNotCollaborators, addErr))
+}
+
+// checkAssignees splits the logins into already assigned, not collaborators and to be assigned
+func (s *Server) checkAssignees(owner, repo string, logins, listOfCurrent []string) ([]string, []string, []string, error) {
+	mapOfCurrent := map[string]bool{}
+	for _, login := range listOfCurrent {
+		mapOfCurrent[login] = true
+	}
+
+	listOfAlreadyAssigned := make([]string, 0)
+	listOfNotCollaborators := make([]string, 0)
+	listOfAssignees := make([]string, 0)
+	for _, login := range logins {
+		if mapOfCurrent[login] {
+			listOfAlreadyAssigned = append(listOfAlreadyAssigned, login)
+			continue
+		}
+		isCollaborator, err := s.CheckIsCollaborator(owner, repo, login)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !isCollaborator {
+			listOfNotCollaborators = append(listOfNotCollaborators, login)
+			continue
+		}
+		listOfAssignees = append(listOfAssignees, login)
+	}
+	glog.Infof("check assignees. to be assigned: %v already assigned: %v not collaborators: %v",
+		listOfAssignees, listOfAlreadyAssigned, listOfNotCollaborators)
+	return listOfAlreadyAssigned, listOfNotCollaborators, listOfAssignees, nil
+}
+
+// buildAssignMessage builds one comment for the result of assign
+func (s *Server) buildAssignMessage(event *gitee.NoteEvent, listOfAssignees, listOfAlreadyAssigned, listOfNotCollaborators []string, err error) string {
+	errMessage := ""
+	if err != nil {
+		errMessage = GetGiteeErrorMessage(err)
+	}
+	return s.RenderMessage(event.Repository.Namespace, event.Repository.Name, event.Comment.User.Login, MessageAssign, MessageData{
+		"IsPullRequest":    *event.NoteableType == "PullRequest",
+		"Assignees":        GetMentions(listOfAssignees),
+		"AlreadyAssigned":  GetMentions(listOfAlreadyAssigned),
+		"NotCollaborators": GetMentions(listOfNotCollaborators),
+		"Error":            errMessage,
+	})
+}
+
+// PatchIssueAssignees sets the assignee and collaborators of issue
+// go-gitee does not support collaborators, so gitee api is called directly
+func (s *Server) PatchIssueAssignees(event *gitee.NoteEvent, assignee string, collaborators []string) error {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	issueNumber := event.Issue.Number
+
+	// set " " to empty the assignee by gitee
+	if assignee == "" {
+		assignee = " "
+	}
+	// build label string
+	var strLabel string
+	for _, l := range event.Issue.Labels {
+		strLabel += l.Name + ","
+	}
+	strLabel = strings.TrimRight(strLabel, ",")
+	if strLabel == "" {
+		strLabel = ","
+	}
+
+	body := map[string]string{
+		"repo":          repo,
+		"assignee":      assignee,
+		"collaborators": strings.Join(collaborators, ","),
+		"labels":        strLabel,
+	}
+	err := s.CallGiteeAPI("PATCH", fmt.Sprintf("/repos/%s/issues/%s", owner, issueNumber), nil, body, nil)
+	if err != nil {
+		glog.Errorf("unable to patch issue assignees: %s err: %v", issueNumber, err)
+		return err
 	}
+	glog.Infof("patch issue assignees successfully: %s", issueNumber)
 	return nil
 }
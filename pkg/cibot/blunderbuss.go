@@ -0,0 +1,252 @@
+package cibot
+
+import (
+	"math/rand"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/config"
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// default number of reviewers to pick
+	defaultReviewerCount = 2
+)
+
+// GetBlunderbuss returns the blunderbuss config of repository, nil if it is not enabled
+// the config of "owner/repo" is preferred to the config of "owner"
+func (s *Server) GetBlunderbuss(owner, repo string) *config.Blunderbuss {
+	i := FindRepositoryConfig(len(s.Config.Blunderbuss), func(i int) []string {
+		return s.Config.Blunderbuss[i].Repositories
+	}, owner, repo)
+	if i < 0 {
+		return nil
+	}
+	return &s.Config.Blunderbuss[i]
+}
+
+// AssignReviewers picks reviewers from OWNERS files of the changed paths
+func (s *Server) AssignReviewers(event *gitee.PullRequestEvent) error {
+	// get basic params
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	prAuthor := event.PullRequest.User.Login
+	prNumber := event.PullRequest.Number
+	branch := event.PullRequest.Base.Ref
+
+	bb := s.GetBlunderbuss(owner, repo)
+	if bb == nil {
+		return nil
+	}
+	count := bb.ReviewerCount
+	if count <= 0 {
+		count = defaultReviewerCount
+	}
+	glog.Infof("assign reviewers started. prAuthor: %s owner: %s repo: %s number: %d count: %d",
+		prAuthor, owner, repo, prNumber, count)
+
+	// weight reviewers by changed lines
+	mapOfWeights, err := s.GetReviewerWeights(owner, repo, prNumber, branch)
+	if err != nil {
+		return err
+	}
+	// author and existing assignees are not picked
+	delete(mapOfWeights, prAuthor)
+	for _, assignee := range event.PullRequest.Assignees {
+		delete(mapOfWeights, assignee.Login)
+	}
+	// weight reviewers by open review load
+	for reviewer, weight := range mapOfWeights {
+		load, err := s.GetReviewLoad(reviewer)
+		if err != nil {
+			return err
+		}
+		mapOfWeights[reviewer] = weight / float64(1+load)
+	}
+	glog.Infof("weights of reviewers: %v", mapOfWeights)
+
+	reviewers := PickReviewers(mapOfWeights, count)
+	if len(reviewers) == 0 {
+		glog.Infof("no reviewers are found in OWNERS files")
+		return nil
+	}
+	glog.Infof("picked reviewers: %v", reviewers)
+
+	// add reviewers in pull request
+	err = s.AddSpecifyAssigneesInPullRequest(owner, repo, prNumber, reviewers)
+	if err != nil {
+		return err
+	}
+	if bb.AddTesters {
+		err = s.AddSpecifyTestersInPullRequest(owner, repo, prNumber, reviewers)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.AddReviewRequests(owner, repo, prNumber, reviewers)
+	if err != nil {
+		return err
+	}
+
+	// add comment
+	body := gitee.PullRequestCommentPostParam{}
+	body.AccessToken = s.Config.GiteeToken
+	body.Body = s.RenderMessage(owner, repo, "", MessageBlunderbuss, MessageData{"Reviewers": GetMentions(reviewers)})
+	_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+	if err != nil {
+		glog.Errorf("unable to add comment in pull request: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetReviewerWeights returns the number of changed lines owned by each reviewer
+func (s *Server) GetReviewerWeights(owner, repo string, prNumber int32, branch string) (map[string]float64, error) {
+	files, err := s.ListPullRequestFiles(owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	mapOfWeights := map[string]float64{}
+	mapOfOwners := map[string][]string{}
+	for _, f := range files {
+		additions, _ := strconv.Atoi(f.Additions)
+		deletions, _ := strconv.Atoi(f.Deletions)
+		lines := additions + deletions
+		// binary files have no changed lines
+		if lines <= 0 {
+			lines = 1
+		}
+		for _, reviewer := range s.GetReviewersOfPath(owner, repo, branch, f.Filename, mapOfOwners) {
+			mapOfWeights[reviewer] += float64(lines)
+		}
+	}
+	return mapOfWeights, nil
+}
+
+// GetReviewersOfPath returns the reviewers in the nearest OWNERS file of path
+// maintainers are used if there are no reviewers in OWNERS file
+// mapOfOwners caches the reviewers of directories
+func (s *Server) GetReviewersOfPath(owner, repo, branch, filename string, mapOfOwners map[string][]string) []string {
+	dir := path.Dir(filename)
+	for {
+		reviewers, ok := mapOfOwners[dir]
+		if !ok {
+			owners, err := s.GetOwnersFile(owner, repo, branch, path.Join(dir, DefaultOwnerFileName))
+			if err == nil {
+				reviewers = owners.Reviewers
+				if len(reviewers) == 0 {
+					reviewers = owners.Maintainers
+				}
+			}
+			mapOfOwners[dir] = reviewers
+		}
+		if len(reviewers) > 0 || dir == "." || dir == "/" {
+			return reviewers
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// PickReviewers picks reviewers randomly by weights
+func PickReviewers(mapOfWeights map[string]float64, count int) []string {
+	candidates := make([]string, 0, len(mapOfWeights))
+	for reviewer := range mapOfWeights {
+		candidates = append(candidates, reviewer)
+	}
+	sort.Strings(candidates)
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	reviewers := make([]string, 0, count)
+	for len(reviewers) < count && len(candidates) > 0 {
+		total := 0.0
+		for _, c := range candidates {
+			total += mapOfWeights[c]
+		}
+		// pick one candidate and remove it from candidates
+		selected := len(candidates) - 1
+		value := r.Float64() * total
+		for i, c := range candidates {
+			value -= mapOfWeights[c]
+			if value < 0 {
+				selected = i
+				break
+			}
+		}
+		reviewers = append(reviewers, candidates[selected])
+		candidates = append(candidates[:selected], candidates[selected+1:]...)
+	}
+	return reviewers
+}
+
+// GetReviewLoad returns the number of open review requests of reviewer
+func (s *Server) GetReviewLoad(reviewer string) (int, error) {
+	var lenReviewRequests int
+	err := database.DBConnection.Model(&database.ReviewRequests{}).
+		Where("reviewer = ?", reviewer).
+		Count(&lenReviewRequests).Error
+	if err != nil {
+		glog.Errorf("unable to get review requests: %v", err)
+	}
+	return lenReviewRequests, err
+}
+
+// AddReviewRequests records the reviewers requested in pull request
+func (s *Server) AddReviewRequests(owner, repo string, prNumber int32, reviewers []string) error {
+	for _, reviewer := range reviewers {
+		var lenReviewRequests int
+		err := database.DBConnection.Model(&database.ReviewRequests{}).
+			Where("owner = ? and repo = ? and number = ? and reviewer = ?", owner, repo, prNumber, reviewer).
+			Count(&lenReviewRequests).Error
+		if err != nil {
+			glog.Errorf("unable to get review requests: %v", err)
+			return err
+		}
+		if lenReviewRequests > 0 {
+			continue
+		}
+
+		addrr := database.ReviewRequests{
+			Owner:    owner,
+			Repo:     repo,
+			Number:   prNumber,
+			Reviewer: reviewer,
+		}
+		err = database.DBConnection.Create(&addrr).Error
+		if err != nil {
+			glog.Errorf("unable to add review requests: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// CloseReviewRequests removes all review requests of pull request
+func (s *Server) CloseReviewRequests(owner, repo string, prNumber int32) error {
+	err := database.DBConnection.
+		Where("owner = ? and repo = ? and number = ?", owner, repo, prNumber).
+		Delete(&database.ReviewRequests{}).Error
+	if err != nil {
+		glog.Errorf("unable to delete review requests: %v", err)
+	}
+	return err
+}
+
+// RemoveReviewRequests removes the review requests of reviewers in pull request
+func (s *Server) RemoveReviewRequests(owner, repo string, prNumber int32, reviewers []string) error {
+	if len(reviewers) == 0 {
+		return nil
+	}
+	err := database.DBConnection.
+		Where("owner = ? and repo = ? and number = ? and reviewer in (?)", owner, repo, prNumber, reviewers).
+		Delete(&database.ReviewRequests{}).Error
+	if err != nil {
+		glog.Errorf("unable to delete review requests: %v", err)
+	}
+	return err
+}
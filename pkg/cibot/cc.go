@@ -0,0 +1,222 @@
+package cibot
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+// GetLoginsFromComment gets the logins in command, e.g. /cc @a @b
+// comment author is returned if there are no logins in command
+func GetLoginsFromComment(reg *regexp.Regexp, comment, commentAuthor string) []string {
+	logins := make([]string, 0)
+	m := reg.FindStringSubmatch(comment)
+	if m != nil && len(m) > 1 {
+		mapOfLogins := map[string]bool{}
+		for _, login := range strings.Fields(m[1]) {
+			login = strings.TrimPrefix(login, "@")
+			if login != "" && !mapOfLogins[login] {
+				mapOfLogins[login] = true
+				logins = append(logins, login)
+			}
+		}
+	}
+	if len(logins) == 0 {
+		logins = append(logins, commentAuthor)
+	}
+	return logins
+}
+
+// GetMentions gets the mentions of logins, e.g. ***@a***, ***@b***
+func GetMentions(logins []string) string {
+	listOfMentions := make([]string, 0, len(logins))
+	for _, login := range logins {
+		listOfMentions = append(listOfMentions, fmt.Sprintf("***@%s***", login))
+	}
+	return strings.Join(listOfMentions, ", ")
+}
+
+// CheckIsCollaborator checks the login is collaborator in repository
+func (s *Server) CheckIsCollaborator(owner, repo, login string) (bool, error) {
+	localVarOptionals := &gitee.GetV5ReposOwnerRepoCollaboratorsUsernameOpts{}
+	localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+	response, err := s.GiteeClient.RepositoriesApi.GetV5ReposOwnerRepoCollaboratorsUsername(
+		s.Context, owner, repo, login, localVarOptionals)
+	// 404 means the login is not collaborator
+	if response != nil && response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		glog.Errorf("unable to check collaborator: %s err: %v", login, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// CC requests reviewers in pull request
+func (s *Server) CC(event *gitee.NoteEvent) error {
+	// handle PullRequest
+	if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			// get basic params
+			comment := event.Comment.Body
+			owner := event.Repository.Namespace
+			repo := event.Repository.Name
+			prAuthor := event.PullRequest.User.Login
+			prNumber := event.PullRequest.Number
+			commentAuthor := event.Comment.User.Login
+			glog.Infof("cc started. comment: %s prAuthor: %s commentAuthor: %s owner: %s repo: %s number: %d",
+				comment, prAuthor, commentAuthor, owner, repo, prNumber)
+
+			// existing reviewers
+			mapOfAssignees := map[string]bool{}
+			for _, assignee := range event.PullRequest.Assignees {
+				mapOfAssignees[assignee.Login] = true
+			}
+			mapOfTesters := map[string]bool{}
+			for _, tester := range event.PullRequest.Testers {
+				mapOfTesters[tester.Login] = true
+			}
+
+			listOfRequested := make([]string, 0)
+			listOfAlreadyRequested := make([]string, 0)
+			listOfNotCollaborators := make([]string, 0)
+			listOfAddAssignees := make([]string, 0)
+			listOfAddTesters := make([]string, 0)
+			for _, login := range GetLoginsFromComment(RegCC, comment, commentAuthor) {
+				if mapOfAssignees[login] && mapOfTesters[login] {
+					listOfAlreadyRequested = append(listOfAlreadyRequested, login)
+					continue
+				}
+				isCollaborator, err := s.CheckIsCollaborator(owner, repo, login)
+				if err != nil {
+					return err
+				}
+				if !isCollaborator {
+					listOfNotCollaborators = append(listOfNotCollaborators, login)
+					continue
+				}
+				listOfRequested = append(listOfRequested, login)
+				if !mapOfAssignees[login] {
+					listOfAddAssignees = append(listOfAddAssignees, login)
+				}
+				if !mapOfTesters[login] {
+					listOfAddTesters = append(listOfAddTesters, login)
+				}
+			}
+			glog.Infof("cc result. requested: %v already requested: %v not collaborators: %v",
+				listOfRequested, listOfAlreadyRequested, listOfNotCollaborators)
+
+			// add assignees and testers
+			err := s.AddSpecifyAssigneesInPullRequest(owner, repo, prNumber, listOfAddAssignees)
+			if err != nil {
+				return err
+			}
+			err = s.AddSpecifyTestersInPullRequest(owner, repo, prNumber, listOfAddTesters)
+			if err != nil {
+				return err
+			}
+			err = s.AddReviewRequests(owner, repo, prNumber, listOfRequested)
+			if err != nil {
+				return err
+			}
+
+			// add comment
+			body := gitee.PullRequestCommentPostParam{}
+			body.AccessToken = s.Config.GiteeToken
+			body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageCC, MessageData{
+				"Requested":        GetMentions(listOfRequested),
+				"AlreadyRequested": GetMentions(listOfAlreadyRequested),
+				"NotCollaborators": GetMentions(listOfNotCollaborators),
+			})
+			_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+			if err != nil {
+				glog.Errorf("unable to add comment in pull request: %v", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnCC removes reviewers in pull request
+func (s *Server) UnCC(event *gitee.NoteEvent) error {
+	// handle PullRequest
+	if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			// get basic params
+			comment := event.Comment.Body
+			owner := event.Repository.Namespace
+			repo := event.Repository.Name
+			prAuthor := event.PullRequest.User.Login
+			prNumber := event.PullRequest.Number
+			commentAuthor := event.Comment.User.Login
+			glog.Infof("uncc started. comment: %s prAuthor: %s commentAuthor: %s owner: %s repo: %s number: %d",
+				comment, prAuthor, commentAuthor, owner, repo, prNumber)
+
+			// existing reviewers
+			mapOfAssignees := map[string]bool{}
+			for _, assignee := range event.PullRequest.Assignees {
+				mapOfAssignees[assignee.Login] = true
+			}
+			mapOfTesters := map[string]bool{}
+			for _, tester := range event.PullRequest.Testers {
+				mapOfTesters[tester.Login] = true
+			}
+
+			listOfRemoved := make([]string, 0)
+			listOfNotRequested := make([]string, 0)
+			listOfRemoveAssignees := make([]string, 0)
+			listOfRemoveTesters := make([]string, 0)
+			for _, login := range GetLoginsFromComment(RegUnCC, comment, commentAuthor) {
+				if !mapOfAssignees[login] && !mapOfTesters[login] {
+					listOfNotRequested = append(listOfNotRequested, login)
+					continue
+				}
+				listOfRemoved = append(listOfRemoved, login)
+				if mapOfAssignees[login] {
+					listOfRemoveAssignees = append(listOfRemoveAssignees, login)
+				}
+				if mapOfTesters[login] {
+					listOfRemoveTesters = append(listOfRemoveTesters, login)
+				}
+			}
+			glog.Infof("uncc result. removed: %v not requested: %v", listOfRemoved, listOfNotRequested)
+
+			// remove assignees and testers
+			err := s.RemoveSpecifyAssigneesInPullRequest(owner, repo, prNumber, listOfRemoveAssignees)
+			if err != nil {
+				return err
+			}
+			err = s.RemoveSpecifyTestersInPullRequest(owner, repo, prNumber, listOfRemoveTesters)
+			if err != nil {
+				return err
+			}
+			err = s.RemoveReviewRequests(owner, repo, prNumber, listOfRemoved)
+			if err != nil {
+				return err
+			}
+
+			// add comment
+			body := gitee.PullRequestCommentPostParam{}
+			body.AccessToken = s.Config.GiteeToken
+			body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageUncc, MessageData{
+				"Removed":      GetMentions(listOfRemoved),
+				"NotRequested": GetMentions(listOfNotRequested),
+			})
+			_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+			if err != nil {
+				glog.Errorf("unable to add comment in pull request: %v", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
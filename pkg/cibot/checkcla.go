@@ -2,195 +2,335 @@ package cibot
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
 	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
 	"github.com/golang/glog"
 )
 
 const (
-	claNotFoundMessage = `Thanks for your pull request.
-**Before we can look at your pull request, you'll need to sign a Contributor License Agreement (CLA).**
-**Please follow instructions at <%s> to sign the CLA.**
-It may take a couple minutes for the CLA signature to be fully registered;
-after that, please reply here with a new comment **/check-cla** and we'll verify.
-- If you've already signed a CLA, it's possible we don't have your Gitee username or you're using a different email address.
-  Check your existing CLA data and verify that your email at <https://gitee.com/profile/emails>.
-- If you have done the above and are still having issues with the CLA being reported as unsigned,
-  send a message to the backup e-mail support address at: %s
-`
-	claFoundMessage = `Thanks for your pull request. you've already signed %s CLA successfully. :wave: `
+	// the email of gitee, which is not a contributor
+	giteeNoreplyEmail = "noreply@gitee.com"
+	// the emails of commit are hidden
+	claHiddenEmail = "the email is hidden"
 )
 
-// CheckCLAByNoteEvent check cla by NoteEvent
-func (s *Server) CheckCLAByNoteEvent(event *gitee.NoteEvent) error {
-	if *event.NoteableType == "PullRequest" {
-		// PullRequest
-		email := event.PullRequest.User.Email
-		// check the email from sender
-		var lenEmail int
-		err := database.DBConnection.Model(&database.CLADetails{}).
-			Where("email = ?", email).Count(&lenEmail).Error
-		if err != nil {
-			glog.Errorf("failed to check user email: %v", err)
-			return err
+var (
+	// RegCoAuthoredBy matches the co-authors in commit message
+	RegCoAuthoredBy = regexp.MustCompile(`(?mi)^Co-authored-by:[ \t]*(.*?)[ \t]*<([^>]*)>[ \t]*$`)
+)
+
+// CLAUnsignedCommit defines the commit whose emails have not signed the cla
+type CLAUnsignedCommit struct {
+	Sha    string
+	Emails string
+}
+
+// GetCLALabel returns the cla label. e.g openeuler-cla/yes
+func (s *Server) GetCLALabel(signed bool) string {
+	if signed {
+		return fmt.Sprintf("%s-cla/yes", strings.ToLower(s.Config.CommunityName))
+	}
+	return fmt.Sprintf("%s-cla/no", strings.ToLower(s.Config.CommunityName))
+}
+
+// GetSignedEmails returns the emails which have signed the cla or are covered by corporate cla,
+// the emails are in lower case
+func GetSignedEmails(emails []string) (map[string]bool, error) {
+	mapOfSigned := map[string]bool{}
+	if len(emails) == 0 {
+		return mapOfSigned, nil
+	}
+	var cds []database.CLADetails
+	err := database.DBConnection.Model(&database.CLADetails{}).
+		Where("email in (?)", emails).Find(&cds).Error
+	if err != nil {
+		glog.Errorf("failed to check user emails: %v", err)
+		return nil, err
+	}
+	for _, cd := range cds {
+		mapOfSigned[strings.ToLower(cd.Email)] = true
+	}
+
+	// the employees of signed corporations
+	mapOfCovered, err := GetCoveredEmails(emails)
+	if err != nil {
+		return nil, err
+	}
+	for email := range mapOfCovered {
+		mapOfSigned[email] = true
+	}
+	return mapOfSigned, nil
+}
+
+// GetCommitEmails returns the emails of author, committer and co-authors of commit
+// the emails of gitee, e.g. the committer of commits on web, are ignored
+func GetCommitEmails(c CommitItem) []string {
+	listOfEmails := make([]string, 0)
+	mapOfEmails := map[string]bool{}
+	addEmail := func(email string) {
+		email = strings.TrimSpace(email)
+		key := strings.ToLower(email)
+		if email == "" || key == giteeNoreplyEmail || mapOfEmails[key] {
+			return
 		}
-		if lenEmail > 0 {
-			// add label openeuler-cla/yes
-			addlabel := &gitee.NoteEvent{}
-			addlabel.PullRequest = event.PullRequest
-			addlabel.Repository = event.Repository
-			addlabel.Comment = &gitee.Note{}
-			addlabel.Comment.Body = fmt.Sprintf(AddClaYes, strings.ToLower(s.Config.CommunityName))
-			err = s.AddLabelInPulRequest(addlabel)
-			if err != nil {
-				return err
-			}
+		mapOfEmails[key] = true
+		listOfEmails = append(listOfEmails, email)
+	}
+	addEmail(c.Commit.Author.Email)
+	addEmail(c.Commit.Committer.Email)
+	for _, m := range RegCoAuthoredBy.FindAllStringSubmatch(c.Commit.Message, -1) {
+		addEmail(m[2])
+	}
+	return listOfEmails
+}
 
-			// remove label openeuler-cla/no
-			removelabel := &gitee.NoteEvent{}
-			removelabel.PullRequest = event.PullRequest
-			removelabel.Repository = event.Repository
-			removelabel.Comment = &gitee.Note{}
-			removelabel.Comment.Body = fmt.Sprintf(RemoveClaNo, strings.ToLower(s.Config.CommunityName))
-			err = s.RemoveLabelInPullRequest(removelabel)
-			if err != nil {
-				return err
-			}
+// GetListOfUnsignedCommits returns the commits whose emails have not signed the cla, and all the checked emails
+// the email of pull request author is checked if no commit is found
+func GetListOfUnsignedCommits(pr *gitee.PullRequest, commits []CommitItem) ([]CLAUnsignedCommit, []string, error) {
+	mapOfCommitEmails := map[string][]string{}
+	listOfShas := make([]string, 0)
+	listOfEmails := make([]string, 0)
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			continue
+		}
+		emails := GetCommitEmails(c)
+		mapOfCommitEmails[c.Sha] = emails
+		listOfShas = append(listOfShas, c.Sha)
+		listOfEmails = append(listOfEmails, emails...)
+	}
+	if len(listOfShas) == 0 {
+		// no commit to check, so the author of pull request is checked
+		listOfShas = append(listOfShas, pr.Head.Sha)
+		mapOfCommitEmails[pr.Head.Sha] = make([]string, 0)
+		if pr.User.Email != "" {
+			mapOfCommitEmails[pr.Head.Sha] = append(mapOfCommitEmails[pr.Head.Sha], pr.User.Email)
+			listOfEmails = append(listOfEmails, pr.User.Email)
+		}
+	}
 
-			// add comment
-			body := gitee.PullRequestCommentPostParam{}
-			body.AccessToken = s.Config.GiteeToken
-			body.Body = fmt.Sprintf(claFoundMessage, s.Config.CommunityName)
-			owner := event.Repository.Namespace
-			repo := event.Repository.Name
-			number := event.PullRequest.Number
-			_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, body)
-			if err != nil {
-				glog.Errorf("unable to add comment in pull request: %v", err)
-				return err
-			}
-		} else {
-			// add label openeuler-cla/no
-			addlabel := &gitee.NoteEvent{}
-			addlabel.PullRequest = event.PullRequest
-			addlabel.Repository = event.Repository
-			addlabel.Comment = &gitee.Note{}
-			addlabel.Comment.Body = fmt.Sprintf(AddClaNo, strings.ToLower(s.Config.CommunityName))
-			err = s.AddLabelInPulRequest(addlabel)
-			if err != nil {
-				return err
+	mapOfSigned, err := GetSignedEmails(listOfEmails)
+	if err != nil {
+		return nil, nil, err
+	}
+	listOfUnsigned := make([]CLAUnsignedCommit, 0)
+	for _, sha := range listOfShas {
+		listOfUnsignedEmails := make([]string, 0)
+		for _, email := range mapOfCommitEmails[sha] {
+			if !mapOfSigned[strings.ToLower(email)] {
+				listOfUnsignedEmails = append(listOfUnsignedEmails, email)
 			}
+		}
+		emails := strings.Join(listOfUnsignedEmails, ", ")
+		if len(mapOfCommitEmails[sha]) == 0 {
+			// the email is hidden, so it can not be checked
+			emails = claHiddenEmail
+		}
+		if emails != "" {
+			listOfUnsigned = append(listOfUnsigned, CLAUnsignedCommit{
+				Sha:    getShortSha(sha),
+				Emails: emails,
+			})
+		}
+	}
+	return listOfUnsigned, listOfEmails, nil
+}
 
-			// remove label openeuler-cla/yes
-			removelabel := &gitee.NoteEvent{}
-			removelabel.PullRequest = event.PullRequest
-			removelabel.Repository = event.Repository
-			removelabel.Comment = &gitee.Note{}
-			removelabel.Comment.Body = fmt.Sprintf(RemoveClaYes, strings.ToLower(s.Config.CommunityName))
-			err = s.RemoveLabelInPullRequest(removelabel)
-			if err != nil {
-				return err
-			}
+// GetListOfUnsignedCommitsInPullRequest lists the commits of pull request,
+// and returns the commits whose emails have not signed the cla, and all the checked emails
+func (s *Server) GetListOfUnsignedCommitsInPullRequest(owner, repo string, pr *gitee.PullRequest) ([]CLAUnsignedCommit, []string, error) {
+	commits, err := s.ListPullRequestCommits(owner, repo, pr.Number)
+	if err != nil {
+		return nil, nil, err
+	}
+	return GetListOfUnsignedCommits(pr, commits)
+}
 
-			// add comment
-			body := gitee.PullRequestCommentPostParam{}
-			body.AccessToken = s.Config.GiteeToken
-			body.Body = fmt.Sprintf(claNotFoundMessage, s.Config.ClaLink, s.Config.ContactEmail)
-			owner := event.Repository.Namespace
-			repo := event.Repository.Name
-			number := event.PullRequest.Number
-			_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, body)
-			if err != nil {
-				glog.Errorf("unable to add comment in pull request: %v", err)
-				return err
-			}
+// CheckCLAInPullRequest checks the cla of all the commit authors in pull request,
+// then updates the cla labels and the cla status comment which lists the unsigned commits
+func (s *Server) CheckCLAInPullRequest(repository *gitee.Project, pr *gitee.PullRequest) error {
+	listOfUnsigned, listOfEmails, err := s.GetListOfUnsignedCommitsInPullRequest(repository.Namespace, repository.Name, pr)
+	if err != nil {
+		return err
+	}
+	return s.UpdateCLAInPullRequest(repository, pr, listOfUnsigned, listOfEmails)
+}
+
+// UpdateCLAInPullRequest updates the cla labels and the cla status comment by the unsigned commits of pull request,
+// and records the checked emails of pull request
+func (s *Server) UpdateCLAInPullRequest(repository *gitee.Project, pr *gitee.PullRequest, listOfUnsigned []CLAUnsignedCommit, listOfEmails []string) error {
+	owner := repository.Namespace
+	repo := repository.Name
+	signed := len(listOfUnsigned) == 0
+	glog.Infof("check cla. owner: %s repo: %s number: %d signed: %v unsigned commits: %v",
+		owner, repo, pr.Number, signed, listOfUnsigned)
+
+	// add label openeuler-cla/yes and remove label openeuler-cla/no, or the opposite
+	labelEvent := &gitee.NoteEvent{}
+	labelEvent.PullRequest = pr
+	labelEvent.Repository = repository
+	labelEvent.Comment = &gitee.Note{}
+	err := s.UpdateSpecifyLabelsInPulRequest(labelEvent,
+		map[string]string{s.GetCLALabel(signed): s.GetCLALabel(signed)},
+		map[string]string{s.GetCLALabel(!signed): s.GetCLALabel(!signed)})
+	if err != nil {
+		return err
+	}
+
+	// record the emails, so the pull request is checked again when they sign the cla,
+	// or the corporate cla does not cover them any more
+	err = SaveCLAPullRequests(owner, repo, pr.Number, listOfEmails)
+	if err != nil {
+		glog.Errorf("failed to save cla pull requests: %v", err)
+	}
+
+	// update the cla status comment, which records the checked head sha
+	message := s.RenderMessage(owner, repo, pr.User.Login, MessageClaFound, nil)
+	if !signed {
+		message = s.RenderMessage(owner, repo, pr.User.Login, MessageClaNotFound, MessageData{
+			"UnsignedCommits": listOfUnsigned,
+		})
+	}
+	message += fmt.Sprintf(ClaCheckedShaHiddenValue, pr.Head.Sha)
+	return s.UpdateStickyCommentInPullRequest(owner, repo, pr.Number, StickyCommentKindCLA, message)
+}
+
+// CheckCLAByPullRequestUpdate checks the cla of pull request again only if the head sha is changed,
+// the updates of title, description or labels do not change the commits
+func (s *Server) CheckCLAByPullRequestUpdate(repository *gitee.Project, pr *gitee.PullRequest) error {
+	comment, err := s.GetStickyCommentInPullRequest(repository.Namespace, repository.Name, pr.Number, StickyCommentKindCLA)
+	if err != nil {
+		return err
+	}
+	if comment != nil {
+		m := RegClaCheckedSha.FindStringSubmatch(comment.Body)
+		if m != nil && m[1] == pr.Head.Sha {
+			glog.Infof("cla is already checked. owner: %s repo: %s number: %d sha: %s",
+				repository.Namespace, repository.Name, pr.Number, pr.Head.Sha)
+			return nil
 		}
 	}
+	return s.CheckCLAInPullRequest(repository, pr)
+}
+
+// CheckCLAByNoteEvent check cla by NoteEvent
+func (s *Server) CheckCLAByNoteEvent(event *gitee.NoteEvent) error {
+	if *event.NoteableType == "PullRequest" {
+		return s.CheckCLAInPullRequest(event.Repository, event.PullRequest)
+	}
 	return nil
 }
 
-// CheckCLAByPullRequestEvent check cla by PullRequestEvent
-func (s *Server) CheckCLAByPullRequestEvent(event *gitee.PullRequestEvent) error {
-	// check the email from sender
-	var lenEmail int
-	err := database.DBConnection.Model(&database.CLADetails{}).
-		Where("email = ?", event.Sender.Email).Count(&lenEmail).Error
+// CheckCLAByPullRequestEvent check cla by PullRequestEvent with the unsigned commits already found
+func (s *Server) CheckCLAByPullRequestEvent(event *gitee.PullRequestEvent, listOfUnsigned []CLAUnsignedCommit, listOfEmails []string) error {
+	return s.UpdateCLAInPullRequest(event.Repository, event.PullRequest, listOfUnsigned, listOfEmails)
+}
+
+// SaveCLAPullRequests records the emails of pull request, the old records are replaced
+func SaveCLAPullRequests(owner, repo string, number int32, emails []string) error {
+	err := DeleteCLAPullRequests(owner, repo, number)
 	if err != nil {
-		glog.Errorf("failed to check user email: %v", err)
 		return err
 	}
-	if lenEmail > 0 {
-		// add label openeuler-cla/yes
-		addlabel := &gitee.NoteEvent{}
-		addlabel.PullRequest = event.PullRequest
-		addlabel.Repository = event.Repository
-		addlabel.Comment = &gitee.Note{}
-		addlabel.Comment.Body = fmt.Sprintf(AddClaYes, strings.ToLower(s.Config.CommunityName))
-		err = s.AddLabelInPulRequest(addlabel)
-		if err != nil {
-			return err
+	mapOfEmails := map[string]bool{}
+	for _, email := range emails {
+		email = strings.ToLower(email)
+		if mapOfEmails[email] {
+			continue
 		}
-
-		// remove label openeuler-cla/no
-		removelabel := &gitee.NoteEvent{}
-		removelabel.PullRequest = event.PullRequest
-		removelabel.Repository = event.Repository
-		removelabel.Comment = &gitee.Note{}
-		removelabel.Comment.Body = fmt.Sprintf(RemoveClaNo, strings.ToLower(s.Config.CommunityName))
-		err = s.RemoveLabelInPullRequest(removelabel)
+		mapOfEmails[email] = true
+		addcpr := database.CLAPullRequests{
+			Owner:  owner,
+			Repo:   repo,
+			Number: number,
+			Email:  email,
+		}
+		err = database.DBConnection.Create(&addcpr).Error
 		if err != nil {
+			glog.Errorf("unable to add cla pull request: %v", err)
 			return err
 		}
+	}
+	return nil
+}
 
-		// add comment
-		body := gitee.PullRequestCommentPostParam{}
-		body.AccessToken = s.Config.GiteeToken
-		body.Body = fmt.Sprintf(claFoundMessage, s.Config.CommunityName)
-		owner := event.Repository.Namespace
-		repo := event.Repository.Name
-		number := event.PullRequest.Number
-		_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, body)
-		if err != nil {
-			glog.Errorf("unable to add comment in pull request: %v", err)
-			return err
+// DeleteCLAPullRequests deletes the emails of pull request
+func DeleteCLAPullRequests(owner, repo string, number int32) error {
+	err := database.DBConnection.Where("owner = ? and repo = ? and number = ?", owner, repo, number).
+		Delete(&database.CLAPullRequests{}).Error
+	if err != nil {
+		glog.Errorf("unable to delete cla pull requests: %v", err)
+	}
+	return err
+}
+
+// RecheckCLAByEmail checks the cla of open pull requests again, which contain the email
+func (s *Server) RecheckCLAByEmail(email string) {
+	var cprs []database.CLAPullRequests
+	err := database.DBConnection.Model(&database.CLAPullRequests{}).
+		Where("email = ?", strings.ToLower(email)).Find(&cprs).Error
+	if err != nil {
+		glog.Errorf("unable to get cla pull requests: %v", err)
+		return
+	}
+	glog.Infof("recheck cla by email: %s pull requests: %d", email, len(cprs))
+	s.recheckCLAPullRequests(cprs)
+}
+
+// RecheckCLAByDomain checks the cla of open pull requests again, which contain the emails of domain
+func (s *Server) RecheckCLAByDomain(domain string) {
+	domain = strings.ToLower(domain)
+	var cprs []database.CLAPullRequests
+	err := database.DBConnection.Model(&database.CLAPullRequests{}).
+		Where("email like ?", "%@"+domain).Find(&cprs).Error
+	if err != nil {
+		glog.Errorf("unable to get cla pull requests: %v", err)
+		return
+	}
+	// "_" and "%" in domain are wildcards of like, which match other domains, so the domain after "@" is compared exactly
+	listOfDomain := make([]database.CLAPullRequests, 0)
+	for _, cpr := range cprs {
+		if cpr.Email[strings.LastIndex(cpr.Email, "@")+1:] == domain {
+			listOfDomain = append(listOfDomain, cpr)
 		}
-	} else {
-		// add label openeuler-cla/no
-		addlabel := &gitee.NoteEvent{}
-		addlabel.PullRequest = event.PullRequest
-		addlabel.Repository = event.Repository
-		addlabel.Comment = &gitee.Note{}
-		addlabel.Comment.Body = fmt.Sprintf(AddClaNo, strings.ToLower(s.Config.CommunityName))
-		err = s.AddLabelInPulRequest(addlabel)
-		if err != nil {
-			return err
+	}
+	glog.Infof("recheck cla by domain: %s pull requests: %d", domain, len(listOfDomain))
+	s.recheckCLAPullRequests(listOfDomain)
+}
+
+// recheckCLAPullRequests checks the cla of pull requests again, each pull request is checked once
+func (s *Server) recheckCLAPullRequests(cprs []database.CLAPullRequests) {
+	mapOfChecked := map[string]bool{}
+	for _, cpr := range cprs {
+		key := fmt.Sprintf("%s/%s/%d", cpr.Owner, cpr.Repo, cpr.Number)
+		if mapOfChecked[key] {
+			continue
 		}
+		mapOfChecked[key] = true
 
-		// remove label openeuler-cla/yes
-		removelabel := &gitee.NoteEvent{}
-		removelabel.PullRequest = event.PullRequest
-		removelabel.Repository = event.Repository
-		removelabel.Comment = &gitee.Note{}
-		removelabel.Comment.Body = fmt.Sprintf(RemoveClaYes, strings.ToLower(s.Config.CommunityName))
-		err = s.RemoveLabelInPullRequest(removelabel)
+		lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
+		lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
+		pr, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumber(s.Context, cpr.Owner, cpr.Repo, cpr.Number, lvos)
 		if err != nil {
-			return err
+			glog.Errorf("unable to get pull request. owner: %s repo: %s number: %d err: %v", cpr.Owner, cpr.Repo, cpr.Number, err)
+			continue
 		}
-
-		// add comment
-		body := gitee.PullRequestCommentPostParam{}
-		body.AccessToken = s.Config.GiteeToken
-		body.Body = fmt.Sprintf(claNotFoundMessage, s.Config.ClaLink, s.Config.ContactEmail)
-		owner := event.Repository.Namespace
-		repo := event.Repository.Name
-		number := event.PullRequest.Number
-		_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, body)
+		if pr.State != "open" {
+			_ = DeleteCLAPullRequests(cpr.Owner, cpr.Repo, cpr.Number)
+			continue
+		}
+		// the repository of pull request is the base repository
+		repository := &gitee.Project{
+			Namespace: cpr.Owner,
+			Name:      cpr.Repo,
+		}
+		err = s.CheckCLAInPullRequest(repository, &pr)
 		if err != nil {
-			glog.Errorf("unable to add comment in pull request: %v", err)
-			return err
+			glog.Errorf("failed to recheck cla. owner: %s repo: %s number: %d err: %v", cpr.Owner, cpr.Repo, cpr.Number, err)
 		}
 	}
-	return nil
 }
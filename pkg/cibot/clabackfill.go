@@ -0,0 +1,65 @@
+package cibot
+
+import (
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// default interval between two pull requests in milliseconds
+	defaultClaBackfillInterval = 1000
+)
+
+// CLABackfillHandler records the emails of open pull requests once at startup,
+// so the pull requests opened before the records are checked again when the cla of emails is changed
+type CLABackfillHandler struct {
+	Server
+}
+
+// Serve checks the open pull requests which have no records
+func (handler *CLABackfillHandler) Serve() {
+	if len(handler.Config.ClaBackfill.Repositories) == 0 {
+		return
+	}
+	interval := handler.Config.ClaBackfill.Interval
+	if interval <= 0 {
+		interval = defaultClaBackfillInterval
+	}
+
+	glog.Info("begin to backfill cla pull requests")
+	for _, r := range handler.ListRepositories(handler.Config.ClaBackfill.Repositories) {
+		prs, err := handler.ListPullRequests(r.Owner, r.Repo, "open")
+		if err != nil {
+			continue
+		}
+		for i := range prs {
+			var lenRecords int
+			err = database.DBConnection.Model(&database.CLAPullRequests{}).
+				Where("owner = ? and repo = ? and number = ?", r.Owner, r.Repo, prs[i].Number).
+				Count(&lenRecords).Error
+			if err != nil {
+				glog.Errorf("unable to get cla pull requests: %v", err)
+				continue
+			}
+			if lenRecords > 0 {
+				continue
+			}
+
+			glog.Infof("backfill cla pull request. owner: %s repo: %s number: %d", r.Owner, r.Repo, prs[i].Number)
+			repository := &gitee.Project{
+				Namespace: r.Owner,
+				Name:      r.Repo,
+			}
+			err = handler.CheckCLAInPullRequest(repository, &prs[i])
+			if err != nil {
+				glog.Errorf("unable to backfill cla pull request. owner: %s repo: %s number: %d err: %v",
+					r.Owner, r.Repo, prs[i].Number, err)
+			}
+			time.Sleep(time.Duration(interval) * time.Millisecond)
+		}
+	}
+	glog.Info("end to backfill cla pull requests")
+}
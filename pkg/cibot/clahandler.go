@@ -1,7 +1,6 @@
 package cibot
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -12,7 +11,7 @@ import (
 )
 
 type CLAHandler struct {
-	Context context.Context
+	Server
 }
 
 type CLARequest struct {
@@ -199,6 +198,9 @@ func (s *CLAHandler) HandleRequest(w http.ResponseWriter, request CLARequest) {
 		return
 	}
 
+	// check the pull requests which are waiting for the email
+	go s.RecheckCLAByEmail(cds.Email)
+
 	// constuct result
 	s.HandleResult(w, CLAResult{
 		IsSuccess: true,
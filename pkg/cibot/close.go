@@ -1,7 +1,6 @@
 package cibot
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/antihax/optional"
@@ -10,10 +9,6 @@ import (
 	"github.com/golang/glog"
 )
 
-const (
-	closeIssueMessage = `this issue is closed by: ***@%s***.`
-)
-
 // Close closes pr or issue
 func (s *Server) Close(event *gitee.NoteEvent) error {
 	// handle PullRequest
@@ -43,23 +38,7 @@ func (s *Server) Close(event *gitee.NoteEvent) error {
 			// permission: admin, write, read, none
 			if permission.Permission == "admin" || permission.Permission == "write" || prAuthor == commentAuthor {
 				//  pr author or permission: admin, write
-				body := gitee.PullRequestUpdateParam{}
-				body.AccessToken = s.Config.GiteeToken
-				body.State = "closed"
-				glog.Infof("invoke api to close: %d", prNumber)
-
-				// patch state
-				_, response, err := s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, prNumber, body)
-				if err != nil {
-					if response.StatusCode == 400 {
-						glog.Infof("close successfully with status code %d: %d", response.StatusCode, prNumber)
-					} else {
-						glog.Errorf("unable to close: %d err: %v", prNumber, err)
-						return err
-					}
-				} else {
-					glog.Infof("close successfully: %v", prNumber)
-				}
+				return s.ClosePullRequest(owner, repo, prNumber)
 			}
 		}
 	} else if *event.NoteableType == "Issue" {
@@ -88,38 +67,14 @@ func (s *Server) Close(event *gitee.NoteEvent) error {
 			// permission: admin, write, read, none
 			if permission.Permission == "admin" || permission.Permission == "write" || issueAuthor == commentAuthor {
 				//  issue author or permission: admin, write
-				body := gitee.IssueUpdateParam{}
-				body.Repo = repo
-				body.AccessToken = s.Config.GiteeToken
-				body.State = "closed"
-				// build label string
-				var strLabel string
-				for _, l := range event.Issue.Labels {
-					strLabel += l.Name + ","
-				}
-				strLabel = strings.TrimRight(strLabel, ",")
-				if strLabel == "" {
-					strLabel = ","
-				}
-				body.Labels = strLabel
-				glog.Infof("invoke api to close: %s", issueNumber)
-
-				// patch state
-				_, response, err := s.GiteeClient.IssuesApi.PatchV5ReposOwnerIssuesNumber(s.Context, owner, issueNumber, body)
+				err = s.CloseIssue(owner, repo, issueNumber, event.Issue.Labels)
 				if err != nil {
-					if response.StatusCode == 400 {
-						glog.Infof("close successfully with status code %d: %s", response.StatusCode, issueNumber)
-					} else {
-						glog.Errorf("unable to close: %s err: %v", issueNumber, err)
-						return err
-					}
-				} else {
-					glog.Infof("close successfully: %v", issueNumber)
+					return err
 				}
 				// add comment
 				bodyComment := gitee.IssueCommentPostParam{}
 				bodyComment.AccessToken = s.Config.GiteeToken
-				bodyComment.Body = fmt.Sprintf(closeIssueMessage, commentAuthor)
+				bodyComment.Body = s.RenderMessage(owner, repo, commentAuthor, MessageCloseIssue, MessageData{})
 				_, _, err = s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, issueNumber, bodyComment)
 				if err != nil {
 					glog.Errorf("unable to add comment in issue: %v", err)
@@ -129,3 +84,58 @@ func (s *Server) Close(event *gitee.NoteEvent) error {
 	}
 	return nil
 }
+
+// ClosePullRequest closes pull request
+func (s *Server) ClosePullRequest(owner, repo string, prNumber int32) error {
+	body := gitee.PullRequestUpdateParam{}
+	body.AccessToken = s.Config.GiteeToken
+	body.State = "closed"
+	glog.Infof("invoke api to close: %d", prNumber)
+
+	// patch state
+	_, response, err := s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, prNumber, body)
+	if err != nil {
+		if response != nil && response.StatusCode == 400 {
+			glog.Infof("close successfully with status code %d: %d", response.StatusCode, prNumber)
+		} else {
+			glog.Errorf("unable to close: %d err: %v", prNumber, err)
+			return err
+		}
+	} else {
+		glog.Infof("close successfully: %v", prNumber)
+	}
+	return nil
+}
+
+// CloseIssue closes issue and keeps the labels
+func (s *Server) CloseIssue(owner, repo, issueNumber string, labels []gitee.Label) error {
+	body := gitee.IssueUpdateParam{}
+	body.Repo = repo
+	body.AccessToken = s.Config.GiteeToken
+	body.State = "closed"
+	// build label string
+	var strLabel string
+	for _, l := range labels {
+		strLabel += l.Name + ","
+	}
+	strLabel = strings.TrimRight(strLabel, ",")
+	if strLabel == "" {
+		strLabel = ","
+	}
+	body.Labels = strLabel
+	glog.Infof("invoke api to close: %s", issueNumber)
+
+	// patch state
+	_, response, err := s.GiteeClient.IssuesApi.PatchV5ReposOwnerIssuesNumber(s.Context, owner, issueNumber, body)
+	if err != nil {
+		if response != nil && response.StatusCode == 400 {
+			glog.Infof("close successfully with status code %d: %s", response.StatusCode, issueNumber)
+		} else {
+			glog.Errorf("unable to close: %s err: %v", issueNumber, err)
+			return err
+		}
+	} else {
+		glog.Infof("close successfully: %v", issueNumber)
+	}
+	return nil
+}
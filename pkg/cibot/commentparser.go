@@ -0,0 +1,237 @@
+package cibot
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// the actions of note event
+	noteActionComment = "comment"
+	noteActionEdited  = "edited"
+	noteActionUpdate  = "update"
+
+	// the commands of comments are kept for 30 days, the comments edited after that are not handled
+	commentCommandsRetention = 30 * 24 * time.Hour
+	// the duration of cleaning up comment commands
+	commentCommandsCleanupDuration = 24 * time.Hour
+)
+
+var (
+	// RegHTMLComment
+	RegHTMLComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// RegInlineCode
+	RegInlineCode = regexp.MustCompile("`[^`\n]*`")
+	// RegCodeFence
+	RegCodeFence = regexp.MustCompile("^ {0,3}(```|~~~)")
+)
+
+// StripComment removes the quoted text, fenced and inline code, and html comments in comment
+// so the commands in them are not handled. the line endings are kept.
+func StripComment(body string) string {
+	body = RegHTMLComment.ReplaceAllString(body, "")
+
+	listOfLines := make([]string, 0)
+	fence := ""
+	for _, line := range strings.Split(body, "\n") {
+		// fenced code
+		if m := RegCodeFence.FindStringSubmatch(line); m != nil {
+			if fence == "" {
+				fence = m[1]
+				continue
+			}
+			if fence == m[1] {
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+		// quoted text
+		if strings.HasPrefix(strings.TrimLeft(line, " \t"), ">") {
+			continue
+		}
+		listOfLines = append(listOfLines, RegInlineCode.ReplaceAllString(line, ""))
+	}
+	return strings.Join(listOfLines, "\n")
+}
+
+// GetCommandLines returns the lines of commands in comment
+func GetCommandLines(body string) []string {
+	listOfCommands := make([]string, 0)
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, "\r \t")
+		if strings.HasPrefix(line, "/") {
+			listOfCommands = append(listOfCommands, line)
+		}
+	}
+	return listOfCommands
+}
+
+// GetKnownCommandLines returns the lines of commands which are handled by bot
+// the lines starting with "/" but not matching any command, e.g. paths, are not returned
+func (s *Server) GetKnownCommandLines(body string) []string {
+	listOfRegs := []*regexp.Regexp{
+		RegLabel, RegRemoveLabel, s.GetRegAddLabel(), s.GetRegRemoveLabel(),
+		RegCheckCLA, RegCheckDCO, RegAddLgtm, RegRemoveLgtm, RegAddApprove, RegRemoveApprove,
+		RegHold, RegHoldCancel, RegMergeMethod, RegClose, RegReOpen,
+		RegAssign, RegUnAssign, RegCC, RegUnCC, RegLifecycle, RegRemoveLifecycle,
+		RegReleaseNote, RegReleaseNoteNone,
+	}
+	listOfCommands := make([]string, 0)
+	for _, line := range GetCommandLines(body) {
+		for _, reg := range listOfRegs {
+			if reg.MatchString(line) {
+				listOfCommands = append(listOfCommands, line)
+				break
+			}
+		}
+	}
+	return listOfCommands
+}
+
+// getCommentCommands returns the record of commands handled in comment
+func getCommentCommands(owner, repo string, commentID int32) (*database.CommentCommands, error) {
+	var ccs []database.CommentCommands
+	err := database.DBConnection.Model(&database.CommentCommands{}).
+		Where("owner = ? and repo = ? and comment_id = ?", owner, repo, commentID).Find(&ccs).Error
+	if err != nil {
+		glog.Errorf("unable to get comment commands: %v", err)
+		return nil, err
+	}
+	if len(ccs) == 0 {
+		return nil, nil
+	}
+	return &ccs[0], nil
+}
+
+// SaveCommentCommands records the commands handled in comment, so they are not handled again when it is edited
+func SaveCommentCommands(owner, repo string, commentID int32, listOfCommands []string) error {
+	cc, err := getCommentCommands(owner, repo, commentID)
+	if err != nil {
+		return err
+	}
+	commands := strings.Join(listOfCommands, "\n")
+	if cc != nil {
+		err = database.DBConnection.Model(cc).Update("commands", commands).Error
+		if err != nil {
+			glog.Errorf("unable to update comment commands: %v", err)
+		}
+		return err
+	}
+
+	addcc := database.CommentCommands{
+		Owner:     owner,
+		Repo:      repo,
+		CommentID: commentID,
+		Commands:  commands,
+	}
+	err = database.DBConnection.Create(&addcc).Error
+	if err != nil {
+		glog.Errorf("unable to add comment commands: %v", err)
+	}
+	return err
+}
+
+// PrepareNoteEvent strips the comment in note event, and keeps the new commands only if the comment is edited
+// it returns false if there is nothing to handle
+func (s *Server) PrepareNoteEvent(event *gitee.NoteEvent) bool {
+	if event.Comment == nil || event.Repository == nil {
+		return false
+	}
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	body := StripComment(event.Comment.Body)
+	listOfCommands := GetCommandLines(body)
+
+	switch *event.Action {
+	case noteActionComment:
+		event.Comment.Body = body
+		// the comment without commands is saved too, so the commands added by editing it are handled
+		err := SaveCommentCommands(owner, repo, event.Comment.Id, listOfCommands)
+		if err != nil {
+			glog.Errorf("failed to save comment commands: %v", err)
+		}
+		return true
+	case noteActionEdited, noteActionUpdate:
+		// the comments of bot are updated by itself
+		if event.Comment.User == nil || event.Comment.User.Login == s.Config.BotName {
+			return false
+		}
+		cc, err := getCommentCommands(owner, repo, event.Comment.Id)
+		if err != nil {
+			return false
+		}
+		// the comment is created before saving commands or cleaned up, so its commands may be handled already
+		if cc == nil {
+			glog.Infof("no commands are saved for edited comment. owner: %s repo: %s comment id: %d",
+				owner, repo, event.Comment.Id)
+			err = SaveCommentCommands(owner, repo, event.Comment.Id, listOfCommands)
+			if err != nil {
+				glog.Errorf("failed to save comment commands: %v", err)
+			}
+			return false
+		}
+		mapOfHandled := map[string]bool{}
+		listOfHandled := make([]string, 0)
+		if cc.Commands != "" {
+			listOfHandled = strings.Split(cc.Commands, "\n")
+		}
+		for _, c := range listOfHandled {
+			mapOfHandled[c] = true
+		}
+
+		// only the commands added by editing are handled
+		listOfNewCommands := make([]string, 0)
+		for _, c := range listOfCommands {
+			if !mapOfHandled[c] {
+				mapOfHandled[c] = true
+				listOfNewCommands = append(listOfNewCommands, c)
+				listOfHandled = append(listOfHandled, c)
+			}
+		}
+		if len(listOfNewCommands) == 0 {
+			return false
+		}
+		glog.Infof("new commands in edited comment. owner: %s repo: %s comment id: %d commands: %v",
+			owner, repo, event.Comment.Id, listOfNewCommands)
+		err = SaveCommentCommands(owner, repo, event.Comment.Id, listOfHandled)
+		if err != nil {
+			return false
+		}
+		event.Comment.Body = strings.Join(listOfNewCommands, "\r\n")
+		return true
+	}
+	return false
+}
+
+// CommentCommandsHandler cleans up the expired commands of comments
+type CommentCommandsHandler struct {
+	Server
+}
+
+// Serve cleans up the comment commands periodically
+func (handler *CommentCommandsHandler) Serve() {
+	for {
+		glog.Info("begin to clean up comment commands")
+		CleanupCommentCommands(time.Now().Add(-commentCommandsRetention))
+		glog.Info("end to clean up comment commands")
+		time.Sleep(commentCommandsCleanupDuration)
+	}
+}
+
+// CleanupCommentCommands deletes the commands of comments which are saved before
+func CleanupCommentCommands(before time.Time) error {
+	err := database.DBConnection.Unscoped().Where("created_at < ?", before).
+		Delete(&database.CommentCommands{}).Error
+	if err != nil {
+		glog.Errorf("unable to clean up comment commands: %v", err)
+	}
+	return err
+}
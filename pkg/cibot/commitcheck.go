@@ -0,0 +1,210 @@
+package cibot
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/config"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+const (
+	// LabelNameDCONo is added if the commits are not signed off by the authors
+	LabelNameDCONo = "dco/no"
+	// LabelNameCommitMsgInvalid is added if the commit messages do not match the rules
+	LabelNameCommitMsgInvalid = "commit-msg/invalid"
+
+	// the length of short sha in messages
+	shortShaLength = 7
+
+	// the types of commit check failures
+	commitCheckFailureTypeDCO            = "dco"
+	commitCheckFailureTypeSubjectLength  = "subject-length"
+	commitCheckFailureTypeSubjectPattern = "subject-pattern"
+)
+
+var (
+	// RegSignedOffBy matches the sign-off lines in commit message
+	RegSignedOffBy = regexp.MustCompile(`(?mi)^Signed-off-by:[ \t]*(.*?)[ \t]*<([^>]*)>[ \t]*$`)
+)
+
+// CommitCheckFailure defines the failure of commit, which is explained by the commit-check-failed message
+type CommitCheckFailure struct {
+	Type             string
+	Sha              string
+	Name             string
+	Email            string
+	MaxSubjectLength int
+	SubjectPattern   string
+}
+
+// GetCommitCheck returns the commit check of repository, nil if it is not enabled
+// the check of "owner/repo" is preferred to the check of "owner"
+func (s *Server) GetCommitCheck(owner, repo string) *config.CommitCheck {
+	i := FindRepositoryConfig(len(s.Config.CommitChecks), func(i int) []string {
+		return s.Config.CommitChecks[i].Repositories
+	}, owner, repo)
+	if i < 0 {
+		return nil
+	}
+	return &s.Config.CommitChecks[i]
+}
+
+// getShortSha returns the short sha of commit
+func getShortSha(sha string) string {
+	if len(sha) > shortShaLength {
+		return sha[:shortShaLength]
+	}
+	return sha
+}
+
+// IsSignedOff checks the commit message has the sign-off line of the author
+func IsSignedOff(message string, author CommitUser) bool {
+	for _, m := range RegSignedOffBy.FindAllStringSubmatch(message, -1) {
+		if strings.EqualFold(strings.TrimSpace(m[2]), author.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCommitMessages checks the commits by the rules, and returns the failures of dco and commit messages
+// the merge commits are not checked
+func CheckCommitMessages(cc *config.CommitCheck, commits []CommitItem) ([]CommitCheckFailure, []CommitCheckFailure, error) {
+	var regSubject *regexp.Regexp
+	if cc.SubjectPattern != "" {
+		var err error
+		regSubject, err = regexp.Compile(cc.SubjectPattern)
+		if err != nil {
+			glog.Errorf("invalid subject pattern: %s err: %v", cc.SubjectPattern, err)
+			return nil, nil, err
+		}
+	}
+
+	listOfDCOFailures := make([]CommitCheckFailure, 0)
+	listOfMessageFailures := make([]CommitCheckFailure, 0)
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			continue
+		}
+		sha := getShortSha(c.Sha)
+		message := strings.Replace(c.Commit.Message, "\r\n", "\n", -1)
+		subject := strings.SplitN(message, "\n", 2)[0]
+
+		if cc.DCO && !IsSignedOff(message, c.Commit.Author) {
+			listOfDCOFailures = append(listOfDCOFailures, CommitCheckFailure{
+				Type:  commitCheckFailureTypeDCO,
+				Sha:   sha,
+				Name:  c.Commit.Author.Name,
+				Email: c.Commit.Author.Email,
+			})
+		}
+		if cc.MaxSubjectLength > 0 && utf8.RuneCountInString(subject) > cc.MaxSubjectLength {
+			listOfMessageFailures = append(listOfMessageFailures, CommitCheckFailure{
+				Type:             commitCheckFailureTypeSubjectLength,
+				Sha:              sha,
+				MaxSubjectLength: cc.MaxSubjectLength,
+			})
+		}
+		if regSubject != nil && !regSubject.MatchString(subject) {
+			listOfMessageFailures = append(listOfMessageFailures, CommitCheckFailure{
+				Type:           commitCheckFailureTypeSubjectPattern,
+				Sha:            sha,
+				SubjectPattern: cc.SubjectPattern,
+			})
+		}
+	}
+	return listOfDCOFailures, listOfMessageFailures, nil
+}
+
+// SyncCommitCheck checks the commits of pull request, and updates the labels and the sticky comment
+// the passed result is only commented if the check failed before or it is forced
+func (s *Server) SyncCommitCheck(repository *gitee.Project, pr *gitee.PullRequest, force bool) error {
+	owner := repository.Namespace
+	repo := repository.Name
+	cc := s.GetCommitCheck(owner, repo)
+	if cc == nil {
+		return nil
+	}
+
+	commits, err := s.ListPullRequestCommits(owner, repo, pr.Number)
+	if err != nil {
+		return err
+	}
+	listOfDCOFailures, listOfMessageFailures, err := CheckCommitMessages(cc, commits)
+	if err != nil {
+		return err
+	}
+	glog.Infof("check commits. owner: %s repo: %s number: %d dco failures: %v message failures: %v",
+		owner, repo, pr.Number, listOfDCOFailures, listOfMessageFailures)
+
+	mapOfAddLabels := map[string]string{}
+	mapOfRemoveLabels := map[string]string{}
+	failedBefore := false
+	for l, failed := range map[string]bool{
+		LabelNameDCONo:            len(listOfDCOFailures) > 0,
+		LabelNameCommitMsgInvalid: len(listOfMessageFailures) > 0,
+	} {
+		hasLabel := HasLabel(pr.Labels, l)
+		failedBefore = failedBefore || hasLabel
+		if failed && !hasLabel {
+			mapOfAddLabels[l] = l
+		} else if !failed && hasLabel {
+			mapOfRemoveLabels[l] = l
+		}
+	}
+	if len(mapOfAddLabels) > 0 {
+		err = s.CreateLabelsIfNotExist(owner, repo, mapOfAddLabels, "")
+		if err != nil {
+			return err
+		}
+	}
+	if len(mapOfAddLabels) > 0 || len(mapOfRemoveLabels) > 0 {
+		labelEvent := &gitee.NoteEvent{}
+		labelEvent.PullRequest = pr
+		labelEvent.Repository = repository
+		labelEvent.Comment = &gitee.Note{}
+		err = s.UpdateSpecifyLabelsInPulRequest(labelEvent, mapOfAddLabels, mapOfRemoveLabels)
+		if err != nil {
+			return err
+		}
+	}
+
+	listOfFailures := append(listOfDCOFailures, listOfMessageFailures...)
+	if len(listOfFailures) == 0 && !failedBefore && !force {
+		return nil
+	}
+	message := s.RenderMessage(owner, repo, getPullRequestAuthor(*pr), MessageCommitCheckPassed, nil)
+	if len(listOfFailures) > 0 {
+		message = s.RenderMessage(owner, repo, getPullRequestAuthor(*pr), MessageCommitCheckFailed, MessageData{
+			"Failures": listOfFailures,
+		})
+	}
+	return s.UpdateStickyCommentInPullRequest(owner, repo, pr.Number, StickyCommentKindCommitCheck, message)
+}
+
+// CheckCommitsByNoteEvent checks the commits of pull request by /check-dco
+func (s *Server) CheckCommitsByNoteEvent(event *gitee.NoteEvent) error {
+	if *event.NoteableType != "PullRequest" || event.PullRequest.State != "open" {
+		return nil
+	}
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	if s.GetCommitCheck(owner, repo) == nil {
+		glog.Infof("commit check is not enabled. owner: %s repo: %s", owner, repo)
+		return nil
+	}
+
+	// the labels in note event may be out of date
+	lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
+	lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
+	pr, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, event.PullRequest.Number, lvos)
+	if err != nil {
+		glog.Errorf("unable to get pull request. err: %v", err)
+		return err
+	}
+	return s.SyncCommitCheck(event.Repository, &pr, true)
+}
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
 type Config struct {
 	GiteeToken               string             `yaml:"giteeToken"`
 	WebhookSecret            string             `yaml:"webhookSecret"`
@@ -16,6 +22,34 @@ type Config struct {
 	ClaLink                  string             `yaml:"claLink"`
 	CommandLink              string             `yaml:"commandLink"`
 	ContactEmail             string             `yaml:"contactEmail"`
+	BlockingLabels           []string           `yaml:"blockingLabels"`
+	RequiredLabels           []string           `yaml:"requiredLabels"`
+	MergeQueue               MergeQueue         `yaml:"mergeQueue"`
+	CIChecks                 []CICheck          `yaml:"ciChecks"`
+	MergeMethod              string             `yaml:"mergeMethod"`
+	MergeMethods             map[string]string  `yaml:"mergeMethods"`
+	NeedsRebase              NeedsRebase        `yaml:"needsRebase"`
+	Blunderbuss              []Blunderbuss      `yaml:"blunderbuss"`
+	ReviewReminder           ReviewReminder     `yaml:"reviewReminder"`
+	Lifecycles               []Lifecycle        `yaml:"lifecycles"`
+	LifecycleDuration        int                `yaml:"lifecycleDuration"`
+	ReleaseNote              ReleaseNote        `yaml:"releaseNote"`
+	Release                  Release            `yaml:"release"`
+	AdminToken               string             `yaml:"adminToken"`
+	PublicEmailDomains       []string           `yaml:"publicEmailDomains"`
+	Size                     Size               `yaml:"size"`
+	LabelRulesFile           string             `yaml:"labelRulesFile"`
+	LabelRules               []LabelRules       `yaml:"labelRules"`
+	LabelDefinitionsFile     string             `yaml:"labelDefinitionsFile"`
+	LabelDefinitions         LabelDefinitions   `yaml:"labelDefinitions"`
+	LabelDefinitionsDuration int                `yaml:"labelDefinitionsDuration"`
+	LabelGroups              []LabelGroup       `yaml:"labelGroups"`
+	LabelAllowlist           []string           `yaml:"labelAllowlist"`
+	RateLimit                RateLimit          `yaml:"rateLimit"`
+	Templates                Templates          `yaml:"templates"`
+	Onboarding               Onboarding         `yaml:"onboarding"`
+	CommitChecks             []CommitCheck      `yaml:"commitChecks"`
+	ClaBackfill              ClaBackfill        `yaml:"claBackfill"`
 }
 
 type WatchProjectFile struct {
@@ -24,3 +58,211 @@ type WatchProjectFile struct {
 	WatchprojectFilePath  string `yaml:"watchprojectFilePath"`
 	WatchProjectFileRef   string `yaml:"watchProjectFileRef"`
 }
+
+type MergeQueue struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	Duration     int      `yaml:"duration"`
+}
+
+// CICheck defines the labels by which the ci reports its result
+// gitee has no api of commit status, so the ci adds the labels in pull request
+type CICheck struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// the label added by ci if it passes, which is required to merge
+	SuccessLabel string `yaml:"successLabel"`
+	// the label added by ci if it fails
+	FailureLabel string `yaml:"failureLabel"`
+}
+
+type NeedsRebase struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// duration between two rounds in seconds
+	Duration int `yaml:"duration"`
+	// interval between two pull requests in milliseconds
+	Interval int `yaml:"interval"`
+}
+
+type ClaBackfill struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// interval between two pull requests in milliseconds
+	Interval int `yaml:"interval"`
+}
+
+type Blunderbuss struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// the number of reviewers to pick
+	ReviewerCount int `yaml:"reviewerCount"`
+	// add the picked reviewers as testers too
+	AddTesters bool `yaml:"addTesters"`
+}
+
+type ReviewReminder struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// duration between two rounds in seconds
+	Duration int `yaml:"duration"`
+	// hours to wait before reminding the reviewers
+	SLA int `yaml:"sla"`
+	// hours to wait before mentioning the approvers
+	EscalationSLA int `yaml:"escalationSla"`
+}
+
+type Lifecycle struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// days of inactivity before marking as stale
+	StaleDays int `yaml:"staleDays"`
+	// days of inactivity before marking stale as rotten
+	RottenDays int `yaml:"rottenDays"`
+	// days of inactivity before closing rotten
+	CloseDays int `yaml:"closeDays"`
+}
+
+type ReleaseNote struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+}
+
+type Release struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+}
+
+type Size struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// the changes in these paths are not counted, e.g. "vendor/" or "*.pb.go"
+	ExcludedPaths []string `yaml:"excludedPaths"`
+	// the minimal changed lines of each size, the default thresholds are used if it is 0
+	S   int `yaml:"s"`
+	M   int `yaml:"m"`
+	L   int `yaml:"l"`
+	XL  int `yaml:"xl"`
+	XXL int `yaml:"xxl"`
+}
+
+// LabelRulesFile defines the file of label rules
+type LabelRulesFile struct {
+	LabelRules []LabelRules `yaml:"labelRules"`
+}
+
+// LoadLabelRules reads the label rules in label rules file
+func (c Config) LoadLabelRules() ([]LabelRules, error) {
+	content, err := ioutil.ReadFile(c.LabelRulesFile)
+	if err != nil {
+		return nil, err
+	}
+	var f LabelRulesFile
+	err = yaml.Unmarshal(content, &f)
+	if err != nil {
+		return nil, err
+	}
+	return f.LabelRules, nil
+}
+
+type RateLimit struct {
+	// the max number of commands sent by one user in one repository during a window, 0 means no limit
+	// the limit is counted per repository, so the user has a separate limit in each repository,
+	// and the users abusing many repositories should be added to the blocklist
+	UserLimit int `yaml:"userLimit"`
+	// the max number of commands sent in one repository during a window, 0 means no limit
+	RepoLimit int `yaml:"repoLimit"`
+	// the window in seconds
+	Window int `yaml:"window"`
+	// the commands of these users are always ignored
+	Blocklist []string `yaml:"blocklist"`
+	// only one warning comment is added during a window when the limit is exceeded
+	QuietMode bool `yaml:"quietMode"`
+}
+
+type LabelGroup struct {
+	// the command and prefix of labels, e.g. "/kind bug" adds "kind/bug"
+	Name string `yaml:"name"`
+	// only one label of the group can be added, e.g. "priority/high" removes "priority/low"
+	Exclusive bool `yaml:"exclusive"`
+	// the command without value adds the label of name, e.g. "/help-wanted" adds "help-wanted"
+	Standalone bool `yaml:"standalone"`
+}
+
+type LabelRules struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// remove the labels whose rules are not matched any more
+	RemoveUnmatched bool `yaml:"removeUnmatched"`
+	// create the labels which are not existing in repository
+	CreateLabels bool `yaml:"createLabels"`
+	// the color of created labels, e.g. "#0e8a16"
+	Color string      `yaml:"color"`
+	Rules []LabelRule `yaml:"rules"`
+}
+
+type LabelRule struct {
+	// the glob patterns of changed files, e.g. "docs/**" or "*.spec"
+	Patterns []string `yaml:"patterns"`
+	Labels   []string `yaml:"labels"`
+}
+
+// LoadLabelDefinitions reads the label definitions in label definitions file
+func (c Config) LoadLabelDefinitions() (LabelDefinitions, error) {
+	var defs LabelDefinitions
+	content, err := ioutil.ReadFile(c.LabelDefinitionsFile)
+	if err != nil {
+		return defs, err
+	}
+	err = yaml.Unmarshal(content, &defs)
+	return defs, err
+}
+
+type LabelDefinitions struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	// the repositories created by bot are always managed
+	Repositories []string          `yaml:"repositories"`
+	Labels       []LabelDefinition `yaml:"labels"`
+}
+
+type LabelDefinition struct {
+	Name string `yaml:"name"`
+	// the color without "#", e.g. "0e8a16"
+	Color string `yaml:"color"`
+	// the old names of label, which are renamed to the name
+	Aliases []string `yaml:"aliases"`
+}
+
+type Templates struct {
+	// the directory of template files, e.g. "templates/zh/tip-bot.tmpl" for community,
+	// "templates/openeuler/zh/tip-bot.tmpl" for organization and "templates/openeuler/ci-bot/zh/tip-bot.tmpl" for repository
+	Directory string `yaml:"directory"`
+	// the locales of messages, e.g. ["zh", "en"] for bilingual messages, the default is ["en"]
+	Locales []string `yaml:"locales"`
+	// the locales of "owner/repo" or "owner" for all repositories in organization
+	RepoLocales map[string][]string `yaml:"repoLocales"`
+	// the locale of user, e.g. {"login": "en"}, which is preferred to the locales of repository
+	UserLocales map[string]string `yaml:"userLocales"`
+}
+
+type Onboarding struct {
+	// "repository" detects first-time contributors to each repository (default),
+	// "organization" detects first-time contributors to the whole organization,
+	// only by the contributors recorded by bot, so the contributions before the bot is deployed are not known
+	Scope string `yaml:"scope"`
+	// the link of contribution docs in onboarding comment
+	ContributingLink string `yaml:"contributingLink"`
+	// no welcome comment for regular contributors, otherwise a short one is added
+	SkipRegularWelcome bool `yaml:"skipRegularWelcome"`
+}
+
+type CommitCheck struct {
+	// "owner/repo" or "owner" for all repositories in organization
+	Repositories []string `yaml:"repositories"`
+	// each commit requires a "Signed-off-by" line of the commit author
+	DCO bool `yaml:"dco"`
+	// the max length of commit subject, 0 means no limit
+	MaxSubjectLength int `yaml:"maxSubjectLength"`
+	// the regular expression which commit subject must match, e.g. "^(feat|fix|docs): "
+	SubjectPattern string `yaml:"subjectPattern"`
+}
@@ -0,0 +1,478 @@
+package cibot
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"github.com/golang/glog"
+)
+
+const (
+	// the types of cla in cla request
+	CLATypeIndividual = 0
+	CLATypeCorporate  = 1
+
+	// the types of corporation members
+	CorporationMemberTypeDomain = "domain"
+	CorporationMemberTypeEmail  = "email"
+
+	// the header of corporate cla manager token
+	managerTokenHeader = "X-Manager-Token"
+	// the bytes of generated manager token
+	managerTokenLength = 32
+)
+
+// the public email domains which can not be the domains of corporation, if they are not configured
+var defaultPublicEmailDomains = []string{
+	"gmail.com", "outlook.com", "hotmail.com", "live.com", "yahoo.com", "icloud.com",
+	"qq.com", "foxmail.com", "163.com", "126.com", "yeah.net", "sina.com", "sohu.com", "aliyun.com",
+}
+
+// CorporationManagersHandler issues the tokens of corporate cla managers, which requires the admin token
+type CorporationManagersHandler struct {
+	Server
+}
+
+// CorporationMembersHandler lists, adds and removes the members of corporation, which requires the manager token
+type CorporationMembersHandler struct {
+	Server
+}
+
+// CorporationManagerRequest defines the request of issuing manager token
+type CorporationManagerRequest struct {
+	Corporation string `json:"corporation"`
+	Email       string `json:"email"`
+}
+
+// CorporationManagerResult defines the issued manager token, which is only returned once
+type CorporationManagerResult struct {
+	Corporation string `json:"corporation"`
+	Email       string `json:"email"`
+	Token       string `json:"token"`
+}
+
+// CorporationMember defines the email domain or employee email of corporation
+type CorporationMember struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// hashToken returns the sha256 hash of token, only the hash is saved in database
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateToken returns a random token
+func generateToken() (string, error) {
+	b := make([]byte, managerTokenLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// getEmailDomain returns the domain of email in lower case
+func getEmailDomain(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(email[i+1:])
+}
+
+// isPublicEmailDomain checks the email domain is public, which anyone can register
+func (s *Server) isPublicEmailDomain(domain string) bool {
+	listOfDomains := s.Config.PublicEmailDomains
+	if len(listOfDomains) == 0 {
+		listOfDomains = defaultPublicEmailDomains
+	}
+	for _, d := range listOfDomains {
+		if strings.EqualFold(strings.TrimPrefix(d, "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdmin checks the request has the admin token
+func (s *Server) isAdmin(r *http.Request) bool {
+	token := r.Header.Get(adminTokenHeader)
+	return s.Config.AdminToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(s.Config.AdminToken)) == 1
+}
+
+// normalizeCorporationMember checks the member and returns it in lower case
+func normalizeCorporationMember(member CorporationMember) (CorporationMember, error) {
+	value := strings.ToLower(strings.TrimSpace(member.Value))
+	switch member.Type {
+	case CorporationMemberTypeDomain:
+		value = strings.TrimPrefix(value, "@")
+		if value == "" || strings.Contains(value, "@") || !strings.Contains(value, ".") {
+			return member, fmt.Errorf("invalid email domain: %s", member.Value)
+		}
+	case CorporationMemberTypeEmail:
+		if getEmailDomain(value) == "" || strings.HasPrefix(value, "@") {
+			return member, fmt.Errorf("invalid email: %s", member.Value)
+		}
+	default:
+		return member, fmt.Errorf("invalid member type: %s", member.Type)
+	}
+	return CorporationMember{Type: member.Type, Value: value}, nil
+}
+
+// IsCorporationSigned checks the corporation has signed the corporate cla
+func IsCorporationSigned(corporation string) (bool, error) {
+	var lenCorporation int
+	err := database.DBConnection.Model(&database.CLADetails{}).
+		Where("type = ? and corporation = ?", CLATypeCorporate, corporation).Count(&lenCorporation).Error
+	if err != nil {
+		glog.Errorf("failed to check corporation: %v", err)
+		return false, err
+	}
+	return lenCorporation > 0, nil
+}
+
+// getCorporationDomains returns the email domains of the signatories of corporate cla
+func getCorporationDomains(corporation string) (map[string]bool, error) {
+	var cds []database.CLADetails
+	err := database.DBConnection.Model(&database.CLADetails{}).
+		Where("type = ? and corporation = ?", CLATypeCorporate, corporation).Find(&cds).Error
+	if err != nil {
+		glog.Errorf("failed to get corporation signatories: %v", err)
+		return nil, err
+	}
+	mapOfDomains := map[string]bool{}
+	for _, cd := range cds {
+		if domain := getEmailDomain(cd.Email); domain != "" {
+			mapOfDomains[domain] = true
+		}
+	}
+	return mapOfDomains, nil
+}
+
+// getDomainOwner returns the other corporation which has the email domain as member, empty if not existing
+func getDomainOwner(corporation, domain string) (string, error) {
+	var cms []database.CorporationMembers
+	err := database.DBConnection.Model(&database.CorporationMembers{}).
+		Where("type = ? and value = ? and corporation <> ?", CorporationMemberTypeDomain, domain, corporation).
+		Find(&cms).Error
+	if err != nil {
+		glog.Errorf("failed to get corporation of domain: %v", err)
+		return "", err
+	}
+	if len(cms) == 0 {
+		return "", nil
+	}
+	return cms[0].Corporation, nil
+}
+
+// GetCoveredEmails returns the emails which are covered by corporate cla, the emails are in lower case
+// the email is covered if it or its domain is a member of corporation which still signs the corporate cla
+func GetCoveredEmails(emails []string) (map[string]bool, error) {
+	mapOfCovered := map[string]bool{}
+	if len(emails) == 0 {
+		return mapOfCovered, nil
+	}
+	listOfEmails := make([]string, 0, len(emails))
+	listOfDomains := make([]string, 0, len(emails))
+	for _, email := range emails {
+		listOfEmails = append(listOfEmails, strings.ToLower(email))
+		if domain := getEmailDomain(email); domain != "" {
+			listOfDomains = append(listOfDomains, domain)
+		}
+	}
+
+	var cms []database.CorporationMembers
+	err := database.DBConnection.Model(&database.CorporationMembers{}).
+		Where("(type = ? and value in (?)) or (type = ? and value in (?))",
+			CorporationMemberTypeEmail, listOfEmails, CorporationMemberTypeDomain, listOfDomains).
+		Find(&cms).Error
+	if err != nil {
+		glog.Errorf("failed to check corporation members: %v", err)
+		return nil, err
+	}
+	if len(cms) == 0 {
+		return mapOfCovered, nil
+	}
+
+	// the members of corporations whose cla is removed are not covered
+	listOfCorporations := make([]string, 0, len(cms))
+	for _, cm := range cms {
+		listOfCorporations = append(listOfCorporations, cm.Corporation)
+	}
+	var listOfSigned []string
+	err = database.DBConnection.Model(&database.CLADetails{}).
+		Where("type = ? and corporation in (?)", CLATypeCorporate, listOfCorporations).
+		Pluck("corporation", &listOfSigned).Error
+	if err != nil {
+		glog.Errorf("failed to check corporations: %v", err)
+		return nil, err
+	}
+	mapOfSigned := map[string]bool{}
+	for _, c := range listOfSigned {
+		mapOfSigned[c] = true
+	}
+
+	mapOfMembers := map[CorporationMember]bool{}
+	for _, cm := range cms {
+		if mapOfSigned[cm.Corporation] {
+			mapOfMembers[CorporationMember{Type: cm.Type, Value: cm.Value}] = true
+		}
+	}
+	for _, email := range listOfEmails {
+		if mapOfMembers[CorporationMember{Type: CorporationMemberTypeEmail, Value: email}] ||
+			mapOfMembers[CorporationMember{Type: CorporationMemberTypeDomain, Value: getEmailDomain(email)}] {
+			mapOfCovered[email] = true
+		}
+	}
+	return mapOfCovered, nil
+}
+
+// writeJSON writes the result as json
+func writeJSON(w http.ResponseWriter, result interface{}) {
+	datas, err := json.Marshal(result)
+	if err != nil {
+		glog.Errorf("marshal result error: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(datas)
+}
+
+// readJSON reads the request body as json
+func readJSON(r *http.Request, request interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, request)
+}
+
+// ServeHTTP issues the token of corporate cla manager, the old token of manager is replaced
+func (handler *CorporationManagersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !handler.isAdmin(r) {
+		http.Error(w, "invalid admin token", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "unsupport request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CorporationManagerRequest
+	err := readJSON(r, &request)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if request.Corporation == "" || request.Email == "" {
+		http.Error(w, "corporation and email are required", http.StatusBadRequest)
+		return
+	}
+	signed, err := IsCorporationSigned(request.Corporation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !signed {
+		http.Error(w, "corporation has not signed the cla", http.StatusNotFound)
+		return
+	}
+
+	managerToken, err := generateToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = database.DBConnection.Where("corporation = ? and email = ?", request.Corporation, request.Email).
+		Delete(&database.CorporationManagers{}).Error
+	if err != nil {
+		glog.Errorf("unable to delete corporation manager: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	addcm := database.CorporationManagers{
+		Corporation: request.Corporation,
+		Email:       request.Email,
+		Token:       hashToken(managerToken),
+	}
+	err = database.DBConnection.Create(&addcm).Error
+	if err != nil {
+		glog.Errorf("unable to add corporation manager: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	glog.Infof("issue corporation manager token. corporation: %s email: %s", request.Corporation, request.Email)
+
+	writeJSON(w, CorporationManagerResult{
+		Corporation: request.Corporation,
+		Email:       request.Email,
+		Token:       managerToken,
+	})
+}
+
+// getManager returns the corporate cla manager of token, nil if the token is invalid
+func getManager(token string) (*database.CorporationManagers, error) {
+	if token == "" {
+		return nil, nil
+	}
+	var cms []database.CorporationManagers
+	err := database.DBConnection.Model(&database.CorporationManagers{}).
+		Where("token = ?", hashToken(token)).Find(&cms).Error
+	if err != nil {
+		glog.Errorf("unable to get corporation manager: %v", err)
+		return nil, err
+	}
+	if len(cms) == 0 {
+		return nil, nil
+	}
+	return &cms[0], nil
+}
+
+// listCorporationMembers lists the members of corporation
+func listCorporationMembers(corporation string) ([]CorporationMember, error) {
+	var cms []database.CorporationMembers
+	err := database.DBConnection.Model(&database.CorporationMembers{}).
+		Where("corporation = ?", corporation).Order("type, value").Find(&cms).Error
+	if err != nil {
+		glog.Errorf("unable to list corporation members: %v", err)
+		return nil, err
+	}
+	members := make([]CorporationMember, 0, len(cms))
+	for _, cm := range cms {
+		members = append(members, CorporationMember{Type: cm.Type, Value: cm.Value})
+	}
+	return members, nil
+}
+
+// ServeHTTP lists the members of corporation by GET, adds the member by POST and removes the member by DELETE
+// the email domain must be the domain of corporation signatories, not be public and not be a member of other corporations
+// the email must be of the domains of corporation signatories, otherwise the admin token is required to approve it
+// the pull requests which contain the emails of added or removed member are checked again
+func (handler *CorporationMembersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	manager, err := getManager(r.Header.Get(managerTokenHeader))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if manager == nil {
+		http.Error(w, "invalid manager token", http.StatusUnauthorized)
+		return
+	}
+	corporation := manager.Corporation
+
+	if r.Method == "GET" {
+		members, err := listCorporationMembers(corporation)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, members)
+		return
+	}
+	if r.Method != "POST" && r.Method != "DELETE" {
+		http.Error(w, "unsupport request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CorporationMember
+	err = readJSON(r, &request)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	member, err := normalizeCorporationMember(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Method == "POST" && member.Type == CorporationMemberTypeDomain {
+		if handler.isPublicEmailDomain(member.Value) {
+			http.Error(w, fmt.Sprintf("email domain %s is public", member.Value), http.StatusForbidden)
+			return
+		}
+		mapOfDomains, err := getCorporationDomains(corporation)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !mapOfDomains[member.Value] {
+			http.Error(w, fmt.Sprintf("email domain %s is not the domain of corporation signatories", member.Value),
+				http.StatusForbidden)
+			return
+		}
+		owner, err := getDomainOwner(corporation, member.Value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if owner != "" {
+			http.Error(w, fmt.Sprintf("email domain %s is a member of another corporation", member.Value),
+				http.StatusConflict)
+			return
+		}
+	}
+	if r.Method == "POST" && member.Type == CorporationMemberTypeEmail && !handler.isAdmin(r) {
+		domain := getEmailDomain(member.Value)
+		mapOfDomains, err := getCorporationDomains(corporation)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !mapOfDomains[domain] || handler.isPublicEmailDomain(domain) {
+			http.Error(w, fmt.Sprintf("email %s is not of the domains of corporation signatories, "+
+				"which requires the approval of admin", member.Value), http.StatusForbidden)
+			return
+		}
+	}
+
+	// the member is added again or removed
+	err = database.DBConnection.Where("corporation = ? and type = ? and value = ?", corporation, member.Type, member.Value).
+		Delete(&database.CorporationMembers{}).Error
+	if err != nil {
+		glog.Errorf("unable to delete corporation member: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if r.Method == "POST" {
+		addcm := database.CorporationMembers{
+			Corporation: corporation,
+			Type:        member.Type,
+			Value:       member.Value,
+		}
+		err = database.DBConnection.Create(&addcm).Error
+		if err != nil {
+			glog.Errorf("unable to add corporation member: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// check the pull requests which contain the emails of member
+	if member.Type == CorporationMemberTypeDomain {
+		go handler.RecheckCLAByDomain(member.Value)
+	} else {
+		go handler.RecheckCLAByEmail(member.Value)
+	}
+	glog.Infof("%s corporation member. corporation: %s manager: %s type: %s value: %s",
+		r.Method, corporation, manager.Email, member.Type, member.Value)
+
+	members, err := listCorporationMembers(corporation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, members)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// CLAPullRequestsTableName defines
+var CLAPullRequestsTableName = "cla_pull_requests"
+
+// CLAPullRequestsTableSQL matches with CLAPullRequests Object
+var CLAPullRequestsTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	number int(10) DEFAULT NULL,
+	email varchar(255) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, CLAPullRequestsTableName)
+
+// CLAPullRequests defines the emails of open pull requests
+// they are checked again when the email signs the cla, or the corporate cla does not cover the email any more
+type CLAPullRequests struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	Number         int32
+	Email          string
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for CLAPullRequests
+func (cprs CLAPullRequests) GetAdditionalInfo(additionalinfo interface{}) error {
+	if cprs.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(cprs.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (cprs CLAPullRequests) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(cprs)
+	if err != nil {
+		return "", fmt.Errorf("marshal cla pull requests failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// CommandCountsTableName defines
+var CommandCountsTableName = "command_counts"
+
+// CommandCountsTableSQL matches with CommandCounts Object
+var CommandCountsTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	login varchar(255) DEFAULT NULL,
+	window_start timestamp NULL DEFAULT NULL,
+	count int(10) DEFAULT NULL,
+	warned tinyint(1) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, CommandCountsTableName)
+
+// CommandCounts defines the count of commands sent by user or in repository during a window
+type CommandCounts struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	Login          string
+	WindowStart    time.Time
+	Count          int
+	Warned         bool
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for CommandCounts
+func (ccs CommandCounts) GetAdditionalInfo(additionalinfo interface{}) error {
+	if ccs.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(ccs.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (ccs CommandCounts) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(ccs)
+	if err != nil {
+		return "", fmt.Errorf("marshal command counts failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// CommentCommandsTableName defines
+var CommentCommandsTableName = "comment_commands"
+
+// CommentCommandsTableSQL matches with CommentCommands Object
+var CommentCommandsTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	comment_id int(10) DEFAULT NULL,
+	commands text,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, CommentCommandsTableName)
+
+// CommentCommands defines the commands handled in comments
+type CommentCommands struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	CommentID      int32
+	Commands       string `sql:"type:text"`
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for CommentCommands
+func (ccs CommentCommands) GetAdditionalInfo(additionalinfo interface{}) error {
+	if ccs.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(ccs.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (ccs CommentCommands) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(ccs)
+	if err != nil {
+		return "", fmt.Errorf("marshal comment commands failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ContributorsTableName defines
+var ContributorsTableName = "contributors"
+
+// ContributorsTableSQL matches with Contributors Object
+var ContributorsTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	login varchar(255) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, ContributorsTableName)
+
+// Contributors defines the users who have opened pull requests or issues in repositories
+type Contributors struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	Login          string
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for Contributors
+func (cs Contributors) GetAdditionalInfo(additionalinfo interface{}) error {
+	if cs.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(cs.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (cs Contributors) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(cs)
+	if err != nil {
+		return "", fmt.Errorf("marshal contributors failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// CorporationManagersTableName defines
+var CorporationManagersTableName = "corporation_managers"
+
+// CorporationManagersTableSQL matches with CorporationManagers Object
+var CorporationManagersTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	corporation varchar(255) DEFAULT NULL,
+	email varchar(255) DEFAULT NULL,
+	token varchar(255) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, CorporationManagersTableName)
+
+// CorporationManagers defines the managers of corporate cla, who maintain the members of corporation
+// the token is saved as sha256 hash
+type CorporationManagers struct {
+	gorm.Model
+	Corporation    string
+	Email          string
+	Token          string
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for CorporationManagers
+func (cms CorporationManagers) GetAdditionalInfo(additionalinfo interface{}) error {
+	if cms.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(cms.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (cms CorporationManagers) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(cms)
+	if err != nil {
+		return "", fmt.Errorf("marshal corporation managers failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
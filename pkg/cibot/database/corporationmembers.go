@@ -0,0 +1,54 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// CorporationMembersTableName defines
+var CorporationMembersTableName = "corporation_members"
+
+// CorporationMembersTableSQL matches with CorporationMembers Object
+var CorporationMembersTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	corporation varchar(255) DEFAULT NULL,
+	type varchar(255) DEFAULT NULL,
+	value varchar(255) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, CorporationMembersTableName)
+
+// CorporationMembers defines the email domains and employee emails which are covered by corporate cla
+type CorporationMembers struct {
+	gorm.Model
+	Corporation    string
+	Type           string
+	Value          string
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for CorporationMembers
+func (cms CorporationMembers) GetAdditionalInfo(additionalinfo interface{}) error {
+	if cms.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(cms.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (cms CorporationMembers) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(cms)
+	if err != nil {
+		return "", fmt.Errorf("marshal corporation members failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
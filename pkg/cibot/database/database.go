@@ -56,7 +56,7 @@ func ConnectDataBase(config config.Config) (*gorm.DB, error) {
 func UpgradeDataBase(db *gorm.DB) error {
 
 	// upgrades defines
-	upgrades := make([]func() error, 3)
+	upgrades := make([]func() error, 13)
 	upgrades[0] = func() error {
 		// table upgrades
 		if err := db.Exec(UpgradesTableSQL).Error; err != nil {
@@ -90,6 +90,80 @@ func UpgradeDataBase(db *gorm.DB) error {
 		}
 		return nil
 	}
+	upgrades[3] = func() error {
+		// table job_progresses
+		if err := db.Exec(JobProgressesTableSQL).Error; err != nil {
+			return err
+		}
+		// table rebase_notifications
+		if err := db.Exec(RebaseNotificationsTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[4] = func() error {
+		// table review_requests
+		if err := db.Exec(ReviewRequestsTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[5] = func() error {
+		// table review_reminders
+		if err := db.Exec(ReviewRemindersTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[6] = func() error {
+		// table release_notes
+		if err := db.Exec(ReleaseNotesTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[7] = func() error {
+		// table comment_commands
+		if err := db.Exec(CommentCommandsTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[8] = func() error {
+		// table command_counts
+		if err := db.Exec(CommandCountsTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[9] = func() error {
+		// table contributors
+		if err := db.Exec(ContributorsTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[10] = func() error {
+		// table cla_pull_requests
+		if err := db.Exec(CLAPullRequestsTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[11] = func() error {
+		// table corporation_managers
+		if err := db.Exec(CorporationManagersTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
+	upgrades[12] = func() error {
+		// table corporation_members
+		if err := db.Exec(CorporationMembersTableSQL).Error; err != nil {
+			return err
+		}
+		return nil
+	}
 
 	// Get UpgradeID
 	var lastUpgrade = -1
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// JobProgressesTableName defines
+var JobProgressesTableName = "job_progresses"
+
+// JobProgressesTableSQL matches with JobProgresses Object
+var JobProgressesTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	name varchar(255) DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	number int(10) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, JobProgressesTableName)
+
+// JobProgresses defines the last handled item of periodic jobs
+type JobProgresses struct {
+	gorm.Model
+	Name           string
+	Owner          string
+	Repo           string
+	Number         int32
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for JobProgresses
+func (jps JobProgresses) GetAdditionalInfo(additionalinfo interface{}) error {
+	if jps.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(jps.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (jps JobProgresses) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(jps)
+	if err != nil {
+		return "", fmt.Errorf("marshal job progresses failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
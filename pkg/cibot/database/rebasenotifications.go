@@ -0,0 +1,54 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// RebaseNotificationsTableName defines
+var RebaseNotificationsTableName = "rebase_notifications"
+
+// RebaseNotificationsTableSQL matches with RebaseNotifications Object
+var RebaseNotificationsTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	number int(10) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, RebaseNotificationsTableName)
+
+// RebaseNotifications defines the pull requests whose authors are notified to rebase
+type RebaseNotifications struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	Number         int32
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for RebaseNotifications
+func (rns RebaseNotifications) GetAdditionalInfo(additionalinfo interface{}) error {
+	if rns.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(rns.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (rns RebaseNotifications) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(rns)
+	if err != nil {
+		return "", fmt.Errorf("marshal rebase notifications failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
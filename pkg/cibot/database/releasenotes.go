@@ -0,0 +1,58 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ReleaseNotesTableName defines
+var ReleaseNotesTableName = "release_notes"
+
+// ReleaseNotesTableSQL matches with ReleaseNotes Object
+var ReleaseNotesTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	number int(10) DEFAULT NULL,
+	author varchar(255) DEFAULT NULL,
+	note text,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, ReleaseNotesTableName)
+
+// ReleaseNotes defines the release notes of pull requests
+type ReleaseNotes struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	Number         int32
+	Author         string
+	Note           string `sql:"type:text"`
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for ReleaseNotes
+func (rns ReleaseNotes) GetAdditionalInfo(additionalinfo interface{}) error {
+	if rns.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(rns.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (rns ReleaseNotes) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(rns)
+	if err != nil {
+		return "", fmt.Errorf("marshal release notes failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
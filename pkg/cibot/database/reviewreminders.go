@@ -0,0 +1,62 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ReviewRemindersTableName defines
+var ReviewRemindersTableName = "review_reminders"
+
+// ReviewRemindersTableSQL matches with ReviewReminders Object
+var ReviewRemindersTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	number int(10) DEFAULT NULL,
+	reviewer varchar(255) DEFAULT NULL,
+	stage int(10) DEFAULT NULL,
+	requested_at timestamp NULL DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, ReviewRemindersTableName)
+
+// ReviewReminders defines the reminders sent to reviewers who do not review in time
+// one reminder is sent for each stage in the same review request
+type ReviewReminders struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	Number         int32
+	Reviewer       string
+	Stage          int
+	RequestedAt    time.Time
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for ReviewReminders
+func (rrms ReviewReminders) GetAdditionalInfo(additionalinfo interface{}) error {
+	if rrms.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(rrms.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (rrms ReviewReminders) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(rrms)
+	if err != nil {
+		return "", fmt.Errorf("marshal review reminders failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ReviewRequestsTableName defines
+var ReviewRequestsTableName = "review_requests"
+
+// ReviewRequestsTableSQL matches with ReviewRequests Object
+var ReviewRequestsTableSQL = fmt.Sprintf(`CREATE TABLE %s (
+	id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	created_at timestamp NULL DEFAULT NULL,
+	updated_at timestamp NULL DEFAULT NULL,
+	deleted_at timestamp NULL DEFAULT NULL,
+	owner varchar(255) DEFAULT NULL,
+	repo varchar(255) DEFAULT NULL,
+	number int(10) DEFAULT NULL,
+	reviewer varchar(255) DEFAULT NULL,
+	additional_info text,
+	PRIMARY KEY (id)
+  ) ENGINE=InnoDB DEFAULT CHARSET=utf8`, ReviewRequestsTableName)
+
+// ReviewRequests defines the reviewers requested in open pull requests
+type ReviewRequests struct {
+	gorm.Model
+	Owner          string
+	Repo           string
+	Number         int32
+	Reviewer       string
+	AdditionalInfo string `sql:"type:text"`
+}
+
+// GetAdditionalInfo for ReviewRequests
+func (rrs ReviewRequests) GetAdditionalInfo(additionalinfo interface{}) error {
+	if rrs.AdditionalInfo != "" {
+		err := json.Unmarshal([]byte(rrs.AdditionalInfo), &additionalinfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToString for convert
+func (rrs ReviewRequests) ToString() (string, error) {
+	// Marshal datas
+	datas, err := json.Marshal(rrs)
+	if err != nil {
+		return "", fmt.Errorf("marshal review requests failed. Error: %s", err)
+	}
+	return string(datas), nil
+}
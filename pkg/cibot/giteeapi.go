@@ -0,0 +1,98 @@
+package cibot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// the base path of gitee api v5
+	giteeAPIBasePath = "https://gitee.com/api/v5"
+)
+
+// GiteeAPIError defines the error returned by gitee api
+type GiteeAPIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error returns the status and body of response
+func (e GiteeAPIError) Error() string {
+	return fmt.Sprintf("gitee api returns %d: %s", e.StatusCode, string(e.Body))
+}
+
+// GetGiteeErrorMessage returns the message returned by gitee if possible
+func GetGiteeErrorMessage(err error) string {
+	if e, ok := err.(gitee.GenericSwaggerError); ok && len(e.Body()) > 0 {
+		return string(e.Body())
+	}
+	if e, ok := err.(GiteeAPIError); ok && len(e.Body) > 0 {
+		return string(e.Body)
+	}
+	return err.Error()
+}
+
+// CallGiteeAPI calls the gitee api which is not supported or broken in go-gitee
+// path is relative to api v5, e.g. /repos/{owner}/issues/{number}
+// body is encoded as json, and response is decoded into result if result is not nil
+func (s *Server) CallGiteeAPI(method, path string, query url.Values, body interface{}, result interface{}) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("access_token", s.Config.GiteeToken)
+	u := giteeAPIBasePath + path + "?" + query.Encode()
+
+	var reqBody *bytes.Buffer
+	if body != nil {
+		datas, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(datas)
+	} else {
+		reqBody = &bytes.Buffer{}
+	}
+
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(s.Context)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		glog.Errorf("unable to call gitee api: %s %s err: %v", method, path, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = GiteeAPIError{StatusCode: resp.StatusCode, Body: respBody}
+		glog.Errorf("unable to call gitee api: %s %s err: %v", method, path, err)
+		return err
+	}
+
+	if result != nil && len(respBody) > 0 {
+		err = json.Unmarshal(respBody, result)
+		if err != nil {
+			glog.Errorf("unable to decode response of gitee api: %s %s err: %v", method, path, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package cibot
+
+import (
+	"fmt"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+// AddHold adds hold label
+func (s *Server) AddHold(event *gitee.NoteEvent) error {
+	// handle PullRequest
+	if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			// get basic params
+			comment := event.Comment.Body
+			owner := event.Repository.Namespace
+			repo := event.Repository.Name
+			prAuthor := event.PullRequest.User.Login
+			prNumber := event.PullRequest.Number
+			commentAuthor := event.Comment.User.Login
+			glog.Infof("add hold started. comment: %s prAuthor: %s commentAuthor: %s owner: %s repo: %s number: %d",
+				comment, prAuthor, commentAuthor, owner, repo, prNumber)
+
+			// check if current author can hold
+			canHold, err := s.CheckPullRequestPermission(event, commentAuthor)
+			if err != nil {
+				return err
+			}
+			if canHold {
+				// add hold label
+				addlabel := &gitee.NoteEvent{}
+				addlabel.PullRequest = event.PullRequest
+				addlabel.Repository = event.Repository
+				addlabel.Comment = &gitee.Note{}
+				mapOfAddLabels := map[string]string{}
+				mapOfAddLabels[LabelNameHold] = LabelNameHold
+				err = s.CreateLabelsIfNotExist(owner, repo, mapOfAddLabels, "")
+				if err != nil {
+					return err
+				}
+				err = s.AddSpecifyLabelsInPulRequest(addlabel, mapOfAddLabels)
+				if err != nil {
+					return err
+				}
+				// the hold is only replied after the label is applied
+				applied, err := s.HasLabelsInPullRequest(owner, repo, prNumber, mapOfAddLabels)
+				if err != nil {
+					return err
+				}
+				if !applied {
+					glog.Errorf("hold label is not applied. owner: %s repo: %s number: %d", owner, repo, prNumber)
+					return fmt.Errorf("label %s is not applied in pull request %d", LabelNameHold, prNumber)
+				}
+				// add comment
+				body := gitee.PullRequestCommentPostParam{}
+				body.AccessToken = s.Config.GiteeToken
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageHoldAdded, MessageData{"Label": LabelNameHold})
+				_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+				if err != nil {
+					glog.Errorf("unable to add comment in pull request: %v", err)
+					return err
+				}
+			} else {
+				// add comment
+				body := gitee.PullRequestCommentPostParam{}
+				body.AccessToken = s.Config.GiteeToken
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageHoldAddNoPermission, MessageData{"Label": LabelNameHold})
+				_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+				if err != nil {
+					glog.Errorf("unable to add comment in pull request: %v", err)
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RemoveHold removes hold label
+func (s *Server) RemoveHold(event *gitee.NoteEvent) error {
+	// handle PullRequest
+	if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			// get basic params
+			comment := event.Comment.Body
+			owner := event.Repository.Namespace
+			repo := event.Repository.Name
+			prAuthor := event.PullRequest.User.Login
+			prNumber := event.PullRequest.Number
+			commentAuthor := event.Comment.User.Login
+			glog.Infof("remove hold started. comment: %s prAuthor: %s commentAuthor: %s owner: %s repo: %s number: %d",
+				comment, prAuthor, commentAuthor, owner, repo, prNumber)
+
+			// check if current author can cancel hold
+			canHold, err := s.CheckPullRequestPermission(event, commentAuthor)
+			if err != nil {
+				return err
+			}
+			if canHold {
+				// remove hold label
+				removelabel := &gitee.NoteEvent{}
+				removelabel.PullRequest = event.PullRequest
+				removelabel.Repository = event.Repository
+				removelabel.Comment = &gitee.Note{}
+				mapOfRemoveLabels := map[string]string{}
+				mapOfRemoveLabels[LabelNameHold] = LabelNameHold
+				err = s.RemoveSpecifyLabelsInPulRequest(removelabel, mapOfRemoveLabels)
+				if err != nil {
+					return err
+				}
+				// add comment
+				body := gitee.PullRequestCommentPostParam{}
+				body.AccessToken = s.Config.GiteeToken
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageHoldRemoved, MessageData{"Label": LabelNameHold})
+				_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+				if err != nil {
+					glog.Errorf("unable to add comment in pull request: %v", err)
+					return err
+				}
+				// try to merge pr
+				err = s.MergePullRequest(event)
+				if err != nil {
+					return err
+				}
+			} else {
+				// add comment
+				body := gitee.PullRequestCommentPostParam{}
+				body.AccessToken = s.Config.GiteeToken
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageHoldRemoveNoPermission, MessageData{"Label": LabelNameHold})
+				_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+				if err != nil {
+					glog.Errorf("unable to add comment in pull request: %v", err)
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPullRequestPermission checks the author is pr author, collaborator or owner
+func (s *Server) CheckPullRequestPermission(event *gitee.NoteEvent, author string) (bool, error) {
+	// pr author always has permission in the own pr
+	if event.PullRequest.User.Login == author {
+		return true, nil
+	}
+
+	// check if current author has write permission
+	localVarOptionals := &gitee.GetV5ReposOwnerRepoCollaboratorsUsernamePermissionOpts{}
+	localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+	// get permission
+	permission, _, err := s.GiteeClient.RepositoriesApi.GetV5ReposOwnerRepoCollaboratorsUsernamePermission(
+		s.Context, event.Repository.Namespace, event.Repository.Name, author, localVarOptionals)
+	if err != nil {
+		glog.Errorf("unable to get comment author permission: %v", err)
+		return false, err
+	}
+	// permission: admin, write, read, none
+	if permission.Permission == "admin" || permission.Permission == "write" {
+		return true, nil
+	}
+	// check author is owner
+	return s.CheckIsOwner(event, author), nil
+}
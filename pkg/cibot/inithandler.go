@@ -313,6 +313,17 @@ func (handler *InitHandler) addRepositories(owner, repo, description, t string)
 		glog.Errorf("failed to add repositories: %v", err)
 		return err
 	}
+
+	// create labels in repository
+	server := &Server{
+		Config:      handler.Config,
+		Context:     handler.Context,
+		GiteeClient: handler.GiteeClient,
+	}
+	err = server.SyncLabelDefinitions(owner, repo)
+	if err != nil {
+		glog.Errorf("failed to sync label definitions: %v", err)
+	}
 	return nil
 }
 
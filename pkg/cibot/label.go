@@ -1,6 +1,7 @@
 package cibot
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/antihax/optional"
@@ -9,28 +10,14 @@ import (
 	"github.com/golang/glog"
 )
 
-// GetLabelsMap for add or remove labels
-func GetLabelsMap(comment string) map[string]string {
-	// init labels map
-	mapOfLabels := map[string]string{}
-	// split with blank space
-	substrings := strings.Split(strings.TrimSpace(comment), " ")
-	// init label group
-	labelGroup := ""
-	// range over the substrings to get the map of labels
-	for i, l := range substrings {
-		if i == 0 {
-			// first index is the operation to be performed, rest will be the labels
-			// the label group. e.g kind, priority
-			labelGroup = strings.Replace(strings.Replace(l, "/", "", 1), "remove-", "", 1)
-		} else {
-			// the whole label = label group + / + label. e.g kind/feature
-			wholeLabel := labelGroup + "/" + l
-			// use map to avoid the reduplicate label
-			mapOfLabels[wholeLabel] = wholeLabel
+// HasLabel checks the label is existing in list of labels
+func HasLabel(listofLabels []gitee.Label, name string) bool {
+	for _, l := range listofLabels {
+		if l.Name == name {
+			return true
 		}
 	}
-	return mapOfLabels
+	return false
 }
 
 // GetListOfAddLabels return the exact list of add labels
@@ -127,12 +114,25 @@ func (s *Server) AddLabelInPulRequest(event *gitee.NoteEvent) error {
 
 	// /kind label1
 	// /kind label2
+	// /label label3
 	getLabels := strings.Split(comment, "\r\n")
 
 	for _, labelToAdd := range getLabels {
 		// map of add labels
-		mapOfAddLabels := GetLabelsMap(labelToAdd)
-		glog.Infof("map of add labels: %v", mapOfAddLabels)
+		mapOfAddLabels, listOfNotAllowed := s.GetLabelsOfCommand(labelToAdd, false)
+		if len(mapOfAddLabels) == 0 && len(listOfNotAllowed) == 0 {
+			continue
+		}
+		glog.Infof("map of add labels: %v not allowed labels: %v", mapOfAddLabels, listOfNotAllowed)
+		if len(listOfNotAllowed) > 0 {
+			err := s.AddCommentInNoteEvent(event, s.BuildNotAllowedLabelsMessage(event, listOfNotAllowed))
+			if err != nil {
+				return err
+			}
+			if len(mapOfAddLabels) == 0 {
+				continue
+			}
+		}
 
 		// list labels in current gitee repository
 		lvosRepo := &gitee.GetV5ReposOwnerRepoLabelsOpts{}
@@ -144,6 +144,15 @@ func (s *Server) AddLabelInPulRequest(event *gitee.NoteEvent) error {
 		}
 		glog.Infof("list of repository labels: %v", listofRepoLabels)
 
+		// tell the valid labels if some labels are not existing
+		listOfUnknownLabels := GetListOfUnknownLabels(mapOfAddLabels, listofRepoLabels)
+		if len(listOfUnknownLabels) > 0 {
+			err = s.AddCommentInNoteEvent(event, s.BuildUnknownLabelsMessage(event, listOfUnknownLabels, listofRepoLabels))
+			if err != nil {
+				return err
+			}
+		}
+
 		// list labels in current item
 		lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
 		lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
@@ -159,11 +168,18 @@ func (s *Server) AddLabelInPulRequest(event *gitee.NoteEvent) error {
 		listOfAddLabels := GetListOfAddLabels(mapOfAddLabels, listofRepoLabels, listofItemLabels)
 		glog.Infof("list of add labels: %v", listOfAddLabels)
 
+		// the other labels in exclusive groups are removed
+		mapOfExclusiveLabels := s.GetMapOfExclusiveLabels(listOfAddLabels, listofItemLabels)
+		glog.Infof("map of exclusive labels: %v", mapOfExclusiveLabels)
+
 		// invoke gitee api to add labels
 		if len(listOfAddLabels) > 0 {
 			// build label string
 			var strLabel string
 			for _, currentlabel := range listofItemLabels {
+				if _, ok := mapOfExclusiveLabels[currentlabel.Name]; ok {
+					continue
+				}
 				strLabel += currentlabel.Name + ","
 			}
 			for _, addedlabel := range listOfAddLabels {
@@ -178,7 +194,7 @@ func (s *Server) AddLabelInPulRequest(event *gitee.NoteEvent) error {
 			// patch labels
 			_, response, err := s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, number, body)
 			if err != nil {
-				if response.StatusCode == 400 {
+				if response != nil && response.StatusCode == 400 {
 					glog.Infof("add labels successfully with status code %d: %v", response.StatusCode, listOfAddLabels)
 				} else {
 					glog.Errorf("unable to add labels: %v err: %v", listOfAddLabels, err)
@@ -210,12 +226,25 @@ func (s *Server) AddLabelInIssue(event *gitee.NoteEvent) error {
 
 	// /kind label1
 	// /kind label2
+	// /label label3
 	getLabels := strings.Split(comment, "\r\n")
 
 	for _, labelToAdd := range getLabels {
 		// map of add labels
-		mapOfAddLabels := GetLabelsMap(labelToAdd)
-		glog.Infof("map of add labels: %v", mapOfAddLabels)
+		mapOfAddLabels, listOfNotAllowed := s.GetLabelsOfCommand(labelToAdd, false)
+		if len(mapOfAddLabels) == 0 && len(listOfNotAllowed) == 0 {
+			continue
+		}
+		glog.Infof("map of add labels: %v not allowed labels: %v", mapOfAddLabels, listOfNotAllowed)
+		if len(listOfNotAllowed) > 0 {
+			err := s.AddCommentInNoteEvent(event, s.BuildNotAllowedLabelsMessage(event, listOfNotAllowed))
+			if err != nil {
+				return err
+			}
+			if len(mapOfAddLabels) == 0 {
+				continue
+			}
+		}
 
 		// list labels in current gitee repository
 		lvosRepo := &gitee.GetV5ReposOwnerRepoLabelsOpts{}
@@ -227,6 +256,15 @@ func (s *Server) AddLabelInIssue(event *gitee.NoteEvent) error {
 		}
 		glog.Infof("list of repository labels: %v", listofRepoLabels)
 
+		// tell the valid labels if some labels are not existing
+		listOfUnknownLabels := GetListOfUnknownLabels(mapOfAddLabels, listofRepoLabels)
+		if len(listOfUnknownLabels) > 0 {
+			err = s.AddCommentInNoteEvent(event, s.BuildUnknownLabelsMessage(event, listOfUnknownLabels, listofRepoLabels))
+			if err != nil {
+				return err
+			}
+		}
+
 		// list labels in current item
 		lvos := &gitee.GetV5ReposOwnerRepoIssuesNumberLabelsOpts{}
 		lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
@@ -241,11 +279,18 @@ func (s *Server) AddLabelInIssue(event *gitee.NoteEvent) error {
 		listOfAddLabels := GetListOfAddLabels(mapOfAddLabels, listofRepoLabels, listofItemLabels)
 		glog.Infof("list of add labels: %v", listOfAddLabels)
 
+		// the other labels in exclusive groups are removed
+		mapOfExclusiveLabels := s.GetMapOfExclusiveLabels(listOfAddLabels, listofItemLabels)
+		glog.Infof("map of exclusive labels: %v", mapOfExclusiveLabels)
+
 		// invoke gitee api to add labels
 		if len(listOfAddLabels) > 0 {
 			// build label string
 			var strLabel string
 			for _, currentlabel := range listofItemLabels {
+				if _, ok := mapOfExclusiveLabels[currentlabel.Name]; ok {
+					continue
+				}
 				strLabel += currentlabel.Name + ","
 			}
 			for _, addedlabel := range listOfAddLabels {
@@ -302,12 +347,25 @@ func (s *Server) RemoveLabelInPullRequest(event *gitee.NoteEvent) error {
 
 	// /remove-kind label1
 	// /remove-kind label2
+	// /remove-label label3
 	getLables := strings.Split(comment, "\r\n")
 
 	for _, labelToRemove := range getLables {
 		// map of add labels
-		mapOfRemoveLabels := GetLabelsMap(labelToRemove)
-		glog.Infof("map of remove labels: %v", mapOfRemoveLabels)
+		mapOfRemoveLabels, listOfNotAllowed := s.GetLabelsOfCommand(labelToRemove, true)
+		if len(mapOfRemoveLabels) == 0 && len(listOfNotAllowed) == 0 {
+			continue
+		}
+		glog.Infof("map of remove labels: %v not allowed labels: %v", mapOfRemoveLabels, listOfNotAllowed)
+		if len(listOfNotAllowed) > 0 {
+			err := s.AddCommentInNoteEvent(event, s.BuildNotAllowedLabelsMessage(event, listOfNotAllowed))
+			if err != nil {
+				return err
+			}
+			if len(mapOfRemoveLabels) == 0 {
+				continue
+			}
+		}
 
 		// list labels in current item
 		lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
@@ -327,13 +385,15 @@ func (s *Server) RemoveLabelInPullRequest(event *gitee.NoteEvent) error {
 		// invoke gitee api to remove labels
 		if len(listOfRemoveLabels) > 0 {
 			// build label string
+			mapOfRemovedLabels := map[string]bool{}
+			for _, removedlabel := range listOfRemoveLabels {
+				mapOfRemovedLabels[removedlabel] = true
+			}
 			var strLabel string
 			for _, currentlabel := range listofItemLabels {
-				strLabel += currentlabel.Name + ","
-			}
-			for _, removedlabel := range listOfRemoveLabels {
-				strLabel = strings.Replace(strLabel, removedlabel+",", "", 1)
-
+				if !mapOfRemovedLabels[currentlabel.Name] {
+					strLabel += currentlabel.Name + ","
+				}
 			}
 			strLabel = strings.TrimRight(strLabel, ",")
 			// avoid to unable to remove labels when no label is exsit
@@ -348,7 +408,7 @@ func (s *Server) RemoveLabelInPullRequest(event *gitee.NoteEvent) error {
 			// patch labels
 			_, response, err := s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, number, body)
 			if err != nil {
-				if response.StatusCode == 400 {
+				if response != nil && response.StatusCode == 400 {
 					glog.Infof("remove labels successfully with status code %d: %v", response.StatusCode, listOfRemoveLabels)
 				} else {
 					glog.Errorf("unable to remove labels: %v err: %v", listOfRemoveLabels, err)
@@ -380,12 +440,25 @@ func (s *Server) RemoveLabelInIssue(event *gitee.NoteEvent) error {
 
 	// /remove-kind label1
 	// /remove-kind label2
+	// /remove-label label3
 	getLables := strings.Split(comment, "\r\n")
 
 	for _, labelToRemove := range getLables {
 		// map of add labels
-		mapOfRemoveLabels := GetLabelsMap(labelToRemove)
-		glog.Infof("map of remove labels: %v", mapOfRemoveLabels)
+		mapOfRemoveLabels, listOfNotAllowed := s.GetLabelsOfCommand(labelToRemove, true)
+		if len(mapOfRemoveLabels) == 0 && len(listOfNotAllowed) == 0 {
+			continue
+		}
+		glog.Infof("map of remove labels: %v not allowed labels: %v", mapOfRemoveLabels, listOfNotAllowed)
+		if len(listOfNotAllowed) > 0 {
+			err := s.AddCommentInNoteEvent(event, s.BuildNotAllowedLabelsMessage(event, listOfNotAllowed))
+			if err != nil {
+				return err
+			}
+			if len(mapOfRemoveLabels) == 0 {
+				continue
+			}
+		}
 
 		// list labels in current item
 		lvos := &gitee.GetV5ReposOwnerRepoIssuesNumberLabelsOpts{}
@@ -482,7 +555,7 @@ func (s *Server) AddSpecifyLabelsInPulRequest(event *gitee.NoteEvent, mapOfAddLa
 		// patch labels
 		_, response, err := s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, number, body)
 		if err != nil {
-			if response.StatusCode == 400 {
+			if response != nil && response.StatusCode == 400 {
 				glog.Infof("add labels successfully with status code %d: %v", response.StatusCode, listOfAddLabels)
 			} else {
 				glog.Errorf("unable to add labels: %v err: %v", listOfAddLabels, err)
@@ -529,13 +602,15 @@ func (s *Server) RemoveSpecifyLabelsInPulRequest(event *gitee.NoteEvent, mapOfRe
 	// invoke gitee api to remove labels
 	if len(listOfRemoveLabels) > 0 {
 		// build label string
+		mapOfRemovedLabels := map[string]bool{}
+		for _, removedlabel := range listOfRemoveLabels {
+			mapOfRemovedLabels[removedlabel] = true
+		}
 		var strLabel string
 		for _, currentlabel := range listofItemLabels {
-			strLabel += currentlabel.Name + ","
-		}
-		for _, removedlabel := range listOfRemoveLabels {
-			strLabel = strings.Replace(strLabel, removedlabel+",", "", 1)
-
+			if !mapOfRemovedLabels[currentlabel.Name] {
+				strLabel += currentlabel.Name + ","
+			}
 		}
 		strLabel = strings.TrimRight(strLabel, ",")
 		// avoid to unable to remove labels when no label is exsit
@@ -550,7 +625,7 @@ func (s *Server) RemoveSpecifyLabelsInPulRequest(event *gitee.NoteEvent, mapOfRe
 		// patch labels
 		_, response, err := s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, number, body)
 		if err != nil {
-			if response.StatusCode == 400 {
+			if response != nil && response.StatusCode == 400 {
 				glog.Infof("remove labels successfully with status code %d: %v", response.StatusCode, listOfRemoveLabels)
 			} else {
 				glog.Errorf("unable to remove labels: %v err: %v", listOfRemoveLabels, err)
@@ -565,3 +640,216 @@ func (s *Server) RemoveSpecifyLabelsInPulRequest(event *gitee.NoteEvent, mapOfRe
 
 	return nil
 }
+
+// UpdateSpecifyLabelsInPulRequest adds and removes specify labels in pull request by one request
+func (s *Server) UpdateSpecifyLabelsInPulRequest(event *gitee.NoteEvent, mapOfAddLabels, mapOfRemoveLabels map[string]string) error {
+	// get basic informations
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	var number int32
+	if event.PullRequest != nil {
+		number = event.PullRequest.Number
+	}
+	glog.Infof("update specify labels started. owner: %s repo: %s number: %d", owner, repo, number)
+
+	// list labels in current gitee repository
+	lvosRepo := &gitee.GetV5ReposOwnerRepoLabelsOpts{}
+	lvosRepo.AccessToken = optional.NewString(s.Config.GiteeToken)
+	listofRepoLabels, _, err := s.GiteeClient.LabelsApi.GetV5ReposOwnerRepoLabels(s.Context, owner, repo, lvosRepo)
+	if err != nil {
+		glog.Errorf("unable to list repository labels. err: %v", err)
+		return err
+	}
+
+	// list labels in current item
+	lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
+	lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
+	pr, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, number, lvos)
+	if err != nil {
+		glog.Errorf("unable to get pull request. err: %v", err)
+		return err
+	}
+	listofItemLabels := pr.Labels
+	glog.Infof("list of item labels: %v", listofItemLabels)
+
+	// list of add and remove labels
+	listOfAddLabels := GetListOfAddLabels(mapOfAddLabels, listofRepoLabels, listofItemLabels)
+	listOfRemoveLabels := GetListOfRemoveLabels(mapOfRemoveLabels, listofItemLabels)
+	glog.Infof("list of add labels: %v list of remove labels: %v", listOfAddLabels, listOfRemoveLabels)
+
+	// invoke gitee api to update labels
+	if len(listOfAddLabels) > 0 || len(listOfRemoveLabels) > 0 {
+		// build label string
+		mapOfRemovedLabels := map[string]bool{}
+		for _, removedlabel := range listOfRemoveLabels {
+			mapOfRemovedLabels[removedlabel] = true
+		}
+		var strLabel string
+		for _, currentlabel := range listofItemLabels {
+			if !mapOfRemovedLabels[currentlabel.Name] {
+				strLabel += currentlabel.Name + ","
+			}
+		}
+		for _, addedlabel := range listOfAddLabels {
+			strLabel += addedlabel + ","
+		}
+		strLabel = strings.TrimRight(strLabel, ",")
+		// avoid to unable to remove labels when no label is exsit
+		if strLabel == "" {
+			strLabel = ","
+		}
+		body := gitee.PullRequestUpdateParam{}
+		body.AccessToken = s.Config.GiteeToken
+		body.Labels = strLabel
+		glog.Infof("invoke api to update labels: %v", strLabel)
+
+		// patch labels
+		_, response, err := s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, number, body)
+		if err != nil {
+			if response != nil && response.StatusCode == 400 {
+				glog.Infof("update labels successfully with status code %d: %v", response.StatusCode, strLabel)
+			} else {
+				glog.Errorf("unable to update labels: %v err: %v", strLabel, err)
+				return err
+			}
+		} else {
+			glog.Infof("update labels successfully: %v", strLabel)
+		}
+	} else {
+		glog.Infof("no label to update for this event")
+	}
+
+	return nil
+}
+
+// UpdateSpecifyLabelsInIssue adds and removes specify labels in issue by one request
+func (s *Server) UpdateSpecifyLabelsInIssue(event *gitee.NoteEvent, mapOfAddLabels, mapOfRemoveLabels map[string]string) error {
+	// get basic informations
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	var number string
+	if event.Issue != nil {
+		number = event.Issue.Number
+	}
+	glog.Infof("update specify labels started. owner: %s repo: %s number: %s", owner, repo, number)
+
+	// list labels in current gitee repository
+	lvosRepo := &gitee.GetV5ReposOwnerRepoLabelsOpts{}
+	lvosRepo.AccessToken = optional.NewString(s.Config.GiteeToken)
+	listofRepoLabels, _, err := s.GiteeClient.LabelsApi.GetV5ReposOwnerRepoLabels(s.Context, owner, repo, lvosRepo)
+	if err != nil {
+		glog.Errorf("unable to list repository labels. err: %v", err)
+		return err
+	}
+
+	// list labels in current item
+	lvos := &gitee.GetV5ReposOwnerRepoIssuesNumberLabelsOpts{}
+	lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
+	listofItemLabels, _, err := s.GiteeClient.LabelsApi.GetV5ReposOwnerRepoIssuesNumberLabels(s.Context, owner, repo, number, lvos)
+	if err != nil {
+		glog.Errorf("unable to get labels in issue. err: %v", err)
+		return err
+	}
+	glog.Infof("list of item labels: %v", listofItemLabels)
+
+	// list of add and remove labels
+	listOfAddLabels := GetListOfAddLabels(mapOfAddLabels, listofRepoLabels, listofItemLabels)
+	listOfRemoveLabels := GetListOfRemoveLabels(mapOfRemoveLabels, listofItemLabels)
+	glog.Infof("list of add labels: %v list of remove labels: %v", listOfAddLabels, listOfRemoveLabels)
+
+	// invoke gitee api to update labels
+	if len(listOfAddLabels) > 0 || len(listOfRemoveLabels) > 0 {
+		// build label string
+		mapOfRemovedLabels := map[string]bool{}
+		for _, removedlabel := range listOfRemoveLabels {
+			mapOfRemovedLabels[removedlabel] = true
+		}
+		var strLabel string
+		for _, currentlabel := range listofItemLabels {
+			if !mapOfRemovedLabels[currentlabel.Name] {
+				strLabel += currentlabel.Name + ","
+			}
+		}
+		for _, addedlabel := range listOfAddLabels {
+			strLabel += addedlabel + ","
+		}
+		strLabel = strings.TrimRight(strLabel, ",")
+		// avoid to unable to remove labels when no label is exsit
+		if strLabel == "" {
+			strLabel = ","
+		}
+		body := gitee.IssueUpdateParam{}
+		body.Repo = repo
+		body.AccessToken = s.Config.GiteeToken
+		body.Labels = strLabel
+		glog.Infof("invoke api to update labels: %v", strLabel)
+
+		// patch labels
+		_, _, err := s.GiteeClient.IssuesApi.PatchV5ReposOwnerIssuesNumber(s.Context, owner, number, body)
+		if err != nil {
+			glog.Errorf("unable to update labels: %v err: %v", strLabel, err)
+			return err
+		}
+		glog.Infof("update labels successfully: %v", strLabel)
+	} else {
+		glog.Infof("no label to update for this event")
+	}
+
+	return nil
+}
+
+// UpdateSpecifyLabels adds and removes specify labels in the pull request or issue of note event
+func (s *Server) UpdateSpecifyLabels(event *gitee.NoteEvent, mapOfAddLabels, mapOfRemoveLabels map[string]string) error {
+	if *event.NoteableType == "PullRequest" {
+		return s.UpdateSpecifyLabelsInPulRequest(event, mapOfAddLabels, mapOfRemoveLabels)
+	} else if *event.NoteableType == "Issue" {
+		return s.UpdateSpecifyLabelsInIssue(event, mapOfAddLabels, mapOfRemoveLabels)
+	}
+	return nil
+}
+
+// HasLabelsInPullRequest checks the labels are all in the pull request
+// the pull request is got again, because the labels in event are out of date after updating
+func (s *Server) HasLabelsInPullRequest(owner, repo string, number int32, mapOfLabels map[string]string) (bool, error) {
+	lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
+	lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
+	pr, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, number, lvos)
+	if err != nil {
+		glog.Errorf("unable to get pull request. err: %v", err)
+		return false, err
+	}
+	for l := range mapOfLabels {
+		if !HasLabel(pr.Labels, l) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// HasLabelsInIssue checks the labels are all in the issue
+func (s *Server) HasLabelsInIssue(owner, repo, number string, mapOfLabels map[string]string) (bool, error) {
+	var issue IssueItem
+	err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/issues/%s", owner, repo, number), nil, nil, &issue)
+	if err != nil {
+		glog.Errorf("unable to get issue. err: %v", err)
+		return false, err
+	}
+	for l := range mapOfLabels {
+		if !HasLabel(issue.Labels, l) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// HasLabels checks the labels are all in the pull request or issue of note event
+func (s *Server) HasLabels(event *gitee.NoteEvent, mapOfLabels map[string]string) (bool, error) {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	if *event.NoteableType == "PullRequest" {
+		return s.HasLabelsInPullRequest(owner, repo, event.PullRequest.Number, mapOfLabels)
+	} else if *event.NoteableType == "Issue" {
+		return s.HasLabelsInIssue(owner, repo, event.Issue.Number, mapOfLabels)
+	}
+	return false, nil
+}
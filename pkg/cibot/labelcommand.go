@@ -0,0 +1,163 @@
+package cibot
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/config"
+	"gitee.com/openeuler/go-gitee/gitee"
+)
+
+// defaultLabelGroups are used if no label group is configured
+var defaultLabelGroups = []config.LabelGroup{
+	{Name: "kind"},
+	{Name: "priority"},
+	{Name: "sig"},
+}
+
+// GetLabelGroups returns the label groups whose commands add and remove labels
+func (s *Server) GetLabelGroups() []config.LabelGroup {
+	if len(s.Config.LabelGroups) == 0 {
+		return defaultLabelGroups
+	}
+	return s.Config.LabelGroups
+}
+
+// getLabelGroupsExpr returns the regular expression of label group names, e.g. kind|priority|sig
+func (s *Server) getLabelGroupsExpr() string {
+	listOfNames := make([]string, 0)
+	for _, g := range s.GetLabelGroups() {
+		listOfNames = append(listOfNames, regexp.QuoteMeta(g.Name))
+	}
+	return strings.Join(listOfNames, "|")
+}
+
+// GetRegAddLabel returns the regular expression of label group commands, e.g. /kind bug
+func (s *Server) GetRegAddLabel() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?mi)^/(%s)(?:[ \t]+(.*?))?\s*$`, s.getLabelGroupsExpr()))
+}
+
+// GetRegRemoveLabel returns the regular expression of label group remove commands, e.g. /remove-kind bug
+func (s *Server) GetRegRemoveLabel() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?mi)^/remove-(%s)(?:[ \t]+(.*?))?\s*$`, s.getLabelGroupsExpr()))
+}
+
+// getLabelGroup returns the label group of name
+func (s *Server) getLabelGroup(name string) *config.LabelGroup {
+	groups := s.GetLabelGroups()
+	for i := range groups {
+		if strings.EqualFold(groups[i].Name, name) {
+			return &groups[i]
+		}
+	}
+	return nil
+}
+
+// isLabelAllowed checks the label is in the allowlist of /label and /remove-label
+// the allowlist supports the patterns, e.g. area/*
+func (s *Server) isLabelAllowed(label string) bool {
+	for _, a := range s.Config.LabelAllowlist {
+		if a == label {
+			return true
+		}
+		if matched, _ := path.Match(a, label); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLabelsOfCommand returns the labels in one line of command, and the labels which are not allowed
+// e.g. "/kind bug feature" returns kind/bug and kind/feature, "/label needs-test" returns needs-test
+func (s *Server) GetLabelsOfCommand(line string, remove bool) (map[string]string, []string) {
+	regLabel, regGroup := RegLabel, s.GetRegAddLabel()
+	if remove {
+		regLabel, regGroup = RegRemoveLabel, s.GetRegRemoveLabel()
+	}
+
+	mapOfLabels := map[string]string{}
+	listOfNotAllowed := make([]string, 0)
+	if m := regLabel.FindStringSubmatch(line); m != nil {
+		for _, l := range strings.Fields(m[1]) {
+			if s.isLabelAllowed(l) {
+				mapOfLabels[l] = l
+			} else {
+				listOfNotAllowed = append(listOfNotAllowed, l)
+			}
+		}
+	} else if m := regGroup.FindStringSubmatch(line); m != nil {
+		g := s.getLabelGroup(m[1])
+		if g == nil {
+			return mapOfLabels, listOfNotAllowed
+		}
+		values := strings.Fields(m[2])
+		if len(values) == 0 && g.Standalone {
+			mapOfLabels[g.Name] = g.Name
+		}
+		for _, v := range values {
+			// the whole label = label group + / + label. e.g kind/feature
+			wholeLabel := g.Name + "/" + v
+			mapOfLabels[wholeLabel] = wholeLabel
+		}
+	}
+	return s.ResolveLabelAliases(mapOfLabels), listOfNotAllowed
+}
+
+// GetMapOfExclusiveLabels returns the labels which should be removed when adding the labels in exclusive groups
+// e.g. adding priority/high removes priority/low
+func (s *Server) GetMapOfExclusiveLabels(listOfAddLabels []string, listofItemLabels []gitee.Label) map[string]string {
+	mapOfAddLabels := map[string]bool{}
+	for _, l := range listOfAddLabels {
+		mapOfAddLabels[l] = true
+	}
+
+	mapOfExclusiveLabels := map[string]string{}
+	for _, g := range s.GetLabelGroups() {
+		if !g.Exclusive {
+			continue
+		}
+		prefix := g.Name + "/"
+		added := false
+		for _, l := range listOfAddLabels {
+			if strings.HasPrefix(l, prefix) {
+				added = true
+				break
+			}
+		}
+		if !added {
+			continue
+		}
+		for _, l := range listofItemLabels {
+			if strings.HasPrefix(l.Name, prefix) && !mapOfAddLabels[l.Name] {
+				mapOfExclusiveLabels[l.Name] = l.Name
+			}
+		}
+	}
+	return mapOfExclusiveLabels
+}
+
+// BuildNotAllowedLabelsMessage builds the comment which lists the allowed labels of /label and /remove-label
+func (s *Server) BuildNotAllowedLabelsMessage(event *gitee.NoteEvent, listOfNotAllowed []string) string {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	commentAuthor := event.Comment.User.Login
+	listOfLabels := make([]string, 0, len(listOfNotAllowed))
+	for _, l := range listOfNotAllowed {
+		listOfLabels = append(listOfLabels, fmt.Sprintf("***%s***", l))
+	}
+	if len(s.Config.LabelAllowlist) == 0 {
+		return s.RenderMessage(owner, repo, commentAuthor, MessageLabelNoAllowlist, MessageData{
+			"Labels": strings.Join(listOfLabels, ", "),
+		})
+	}
+	listOfAllowed := make([]string, 0, len(s.Config.LabelAllowlist))
+	for _, l := range s.Config.LabelAllowlist {
+		listOfAllowed = append(listOfAllowed, fmt.Sprintf("***%s***", l))
+	}
+	return s.RenderMessage(owner, repo, commentAuthor, MessageLabelNotAllowed, MessageData{
+		"Labels":        strings.Join(listOfLabels, ", "),
+		"AllowedLabels": strings.Join(listOfAllowed, ", "),
+	})
+}
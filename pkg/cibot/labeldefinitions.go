@@ -0,0 +1,258 @@
+package cibot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+const (
+	// default duration of label definitions in seconds
+	defaultLabelDefinitionsDuration = 3600
+)
+
+// LabelDefinitionsHandler creates and updates the labels of managed repositories by label definitions
+type LabelDefinitionsHandler struct {
+	Server
+}
+
+// Serve syncs the label definitions periodically
+func (handler *LabelDefinitionsHandler) Serve() {
+	if len(handler.Config.LabelDefinitions.Labels) == 0 {
+		return
+	}
+
+	for {
+		duration := handler.Config.LabelDefinitionsDuration
+		if duration <= 0 {
+			duration = defaultLabelDefinitionsDuration
+		}
+		glog.Info("begin to sync label definitions")
+		handler.sync()
+		glog.Info("end to sync label definitions")
+		time.Sleep(time.Duration(duration) * time.Second)
+	}
+}
+
+// sync syncs the label definitions in configured repositories and repositories created by bot
+func (handler *LabelDefinitionsHandler) sync() {
+	repositories := handler.ListRepositories(handler.Config.LabelDefinitions.Repositories)
+
+	var rs []database.Repositories
+	err := database.DBConnection.Model(&database.Repositories{}).Find(&rs).Error
+	if err != nil {
+		glog.Errorf("unable to get repositories: %v", err)
+	}
+	for _, r := range rs {
+		repositories = append(repositories, RepositoryName{Owner: r.Owner, Repo: r.Repo})
+	}
+
+	mapOfRepositories := map[string]bool{}
+	for _, r := range repositories {
+		key := r.Owner + "/" + r.Repo
+		if mapOfRepositories[key] {
+			continue
+		}
+		mapOfRepositories[key] = true
+		err = handler.SyncLabelDefinitions(r.Owner, r.Repo)
+		if err != nil {
+			glog.Errorf("unable to sync label definitions. owner: %s repo: %s err: %v", r.Owner, r.Repo, err)
+		}
+	}
+}
+
+// normalizeLabelColor returns the color in lower case without "#"
+func normalizeLabelColor(color string) string {
+	return strings.ToLower(strings.TrimPrefix(color, "#"))
+}
+
+// SyncLabelDefinitions creates the defined labels in repository
+// the labels named by aliases are renamed, and the colors are updated if they are changed
+func (s *Server) SyncLabelDefinitions(owner, repo string) error {
+	defs := s.Config.LabelDefinitions.Labels
+	if len(defs) == 0 {
+		return nil
+	}
+
+	lvosRepo := &gitee.GetV5ReposOwnerRepoLabelsOpts{}
+	lvosRepo.AccessToken = optional.NewString(s.Config.GiteeToken)
+	listofRepoLabels, _, err := s.GiteeClient.LabelsApi.GetV5ReposOwnerRepoLabels(s.Context, owner, repo, lvosRepo)
+	if err != nil {
+		glog.Errorf("unable to list repository labels. err: %v", err)
+		return err
+	}
+	mapOfRepoLabels := map[string]gitee.Label{}
+	for _, l := range listofRepoLabels {
+		mapOfRepoLabels[l.Name] = l
+	}
+
+	for _, def := range defs {
+		color := normalizeLabelColor(def.Color)
+		if color == "" {
+			color = defaultLabelColor
+		}
+
+		// update the color of existing label
+		if l, ok := mapOfRepoLabels[def.Name]; ok {
+			if normalizeLabelColor(l.Color) == color {
+				continue
+			}
+			glog.Infof("update label color. owner: %s repo: %s label: %s color: %s", owner, repo, def.Name, color)
+			err = s.patchLabel(owner, repo, def.Name, def.Name, color)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// rename the label named by alias
+		renamed := false
+		for _, alias := range def.Aliases {
+			if _, ok := mapOfRepoLabels[alias]; !ok {
+				continue
+			}
+			glog.Infof("rename label. owner: %s repo: %s alias: %s label: %s", owner, repo, alias, def.Name)
+			err = s.patchLabel(owner, repo, alias, def.Name, color)
+			if err != nil {
+				return err
+			}
+			delete(mapOfRepoLabels, alias)
+			renamed = true
+			break
+		}
+		if renamed {
+			continue
+		}
+
+		// create the label
+		glog.Infof("create label. owner: %s repo: %s label: %s color: %s", owner, repo, def.Name, color)
+		localVarOptionals := &gitee.PostV5ReposOwnerRepoLabelsOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+		_, _, err = s.GiteeClient.LabelsApi.PostV5ReposOwnerRepoLabels(s.Context, owner, repo, def.Name, color, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to create label: %s owner: %s repo: %s err: %v", def.Name, owner, repo, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// patchLabel updates the name and color of label
+func (s *Server) patchLabel(owner, repo, originalName, name, color string) error {
+	localVarOptionals := &gitee.PatchV5ReposOwnerRepoLabelsOriginalNameOpts{}
+	localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+	localVarOptionals.Name = optional.NewString(name)
+	localVarOptionals.Color = optional.NewString(color)
+	_, _, err := s.GiteeClient.LabelsApi.PatchV5ReposOwnerRepoLabelsOriginalName(
+		s.Context, owner, repo, UrlEncode(originalName), localVarOptionals)
+	if err != nil {
+		glog.Errorf("unable to update label: %s owner: %s repo: %s err: %v", originalName, owner, repo, err)
+	}
+	return err
+}
+
+// ResolveLabelAliases replaces the aliases by the names in label definitions
+func (s *Server) ResolveLabelAliases(mapOfLabels map[string]string) map[string]string {
+	mapOfAliases := map[string]string{}
+	for _, def := range s.Config.LabelDefinitions.Labels {
+		for _, alias := range def.Aliases {
+			mapOfAliases[alias] = def.Name
+		}
+	}
+
+	mapOfResolved := map[string]string{}
+	for l := range mapOfLabels {
+		if name, ok := mapOfAliases[l]; ok {
+			l = name
+		}
+		mapOfResolved[l] = l
+	}
+	return mapOfResolved
+}
+
+// GetListOfUnknownLabels returns the labels which are not existing in repository
+func GetListOfUnknownLabels(mapOfLabels map[string]string, listofRepoLabels []gitee.Label) []string {
+	listOfUnknownLabels := make([]string, 0)
+	for l := range mapOfLabels {
+		if !HasLabel(listofRepoLabels, l) {
+			listOfUnknownLabels = append(listOfUnknownLabels, l)
+		}
+	}
+	sort.Strings(listOfUnknownLabels)
+	return listOfUnknownLabels
+}
+
+// BuildUnknownLabelsMessage builds the comment which lists the valid labels of the same groups as unknown labels
+// the label definitions are preferred if the repository is managed by them
+func (s *Server) BuildUnknownLabelsMessage(event *gitee.NoteEvent, listOfUnknownLabels []string, listofRepoLabels []gitee.Label) string {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	commentAuthor := event.Comment.User.Login
+	mapOfGroups := map[string]bool{}
+	for _, l := range listOfUnknownLabels {
+		mapOfGroups[strings.SplitN(l, "/", 2)[0]+"/"] = true
+	}
+	inGroups := func(name string) bool {
+		for g := range mapOfGroups {
+			if strings.HasPrefix(name, g) {
+				return true
+			}
+		}
+		return false
+	}
+
+	listOfValidLabels := make([]string, 0)
+	if s.isLabelDefinitionsManaged(owner, repo) {
+		for _, def := range s.Config.LabelDefinitions.Labels {
+			if !inGroups(def.Name) {
+				continue
+			}
+			listOfValidLabels = append(listOfValidLabels, def.Name)
+		}
+	} else {
+		for _, l := range listofRepoLabels {
+			if inGroups(l.Name) {
+				listOfValidLabels = append(listOfValidLabels, l.Name)
+			}
+		}
+		sort.Strings(listOfValidLabels)
+	}
+
+	listOfUnknown := make([]string, 0, len(listOfUnknownLabels))
+	for _, l := range listOfUnknownLabels {
+		listOfUnknown = append(listOfUnknown, fmt.Sprintf("***%s***", l))
+	}
+	if len(listOfValidLabels) == 0 {
+		return s.RenderMessage(owner, repo, commentAuthor, MessageNoValidLabels, MessageData{
+			"Labels": strings.Join(listOfUnknown, ", "),
+		})
+	}
+	return s.RenderMessage(owner, repo, commentAuthor, MessageUnknownLabels, MessageData{
+		"Labels":      strings.Join(listOfUnknown, ", "),
+		"ValidLabels": listOfValidLabels,
+	})
+}
+
+// isLabelDefinitionsManaged checks the labels of repository are managed by label definitions
+func (s *Server) isLabelDefinitionsManaged(owner, repo string) bool {
+	if len(s.Config.LabelDefinitions.Labels) == 0 {
+		return false
+	}
+	if MatchRepository(s.Config.LabelDefinitions.Repositories, owner, repo) {
+		return true
+	}
+	var lenRepositories int
+	err := database.DBConnection.Model(&database.Repositories{}).
+		Where("owner = ? and repo = ?", owner, repo).Count(&lenRepositories).Error
+	if err != nil {
+		glog.Errorf("unable to get repositories: %v", err)
+		return false
+	}
+	return lenRepositories > 0
+}
@@ -0,0 +1,187 @@
+package cibot
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/config"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+const (
+	// default color of created labels
+	defaultLabelColor = "0e8a16"
+)
+
+// GetLabelRules returns the label rules of repository, nil if it is not enabled
+// the rules of "owner/repo" are preferred to the rules of "owner"
+func (s *Server) GetLabelRules(owner, repo string) *config.LabelRules {
+	i := FindRepositoryConfig(len(s.Config.LabelRules), func(i int) []string {
+		return s.Config.LabelRules[i].Repositories
+	}, owner, repo)
+	if i < 0 {
+		return nil
+	}
+	return &s.Config.LabelRules[i]
+}
+
+// MatchPattern checks the file path matches the glob pattern
+// the pattern without "/" matches the file name in any directory, e.g. "*.spec"
+// "**" matches any number of directories, e.g. "docs/**"
+func MatchPattern(pattern, filename string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(filename))
+		return matched
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				if i+2 < len(pattern) && pattern[i+2] == '/' {
+					// "**/" matches zero or more directories
+					expr.WriteString("(?:.*/)?")
+					i += 2
+				} else {
+					expr.WriteString(".*")
+					i++
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	reg, err := regexp.Compile(expr.String())
+	if err != nil {
+		glog.Errorf("invalid pattern: %s err: %v", pattern, err)
+		return false
+	}
+	return reg.MatchString(filename)
+}
+
+// SyncLabelRules applies the labels whose patterns match the changed files of pull request
+// the labels whose rules are not matched any more are removed if it is configured
+func (s *Server) SyncLabelRules(repository *gitee.Project, pr *gitee.PullRequest) error {
+	owner := repository.Namespace
+	repo := repository.Name
+	lr := s.GetLabelRules(owner, repo)
+	if lr == nil {
+		return nil
+	}
+
+	files, err := s.ListPullRequestFiles(owner, repo, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	mapOfMatched := map[string]string{}
+	mapOfRuleLabels := map[string]string{}
+	for _, rule := range lr.Rules {
+		matched := false
+		for _, f := range files {
+			for _, p := range rule.Patterns {
+				if MatchPattern(p, f.Filename) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				break
+			}
+		}
+		for _, l := range rule.Labels {
+			mapOfRuleLabels[l] = l
+			if matched {
+				mapOfMatched[l] = l
+			}
+		}
+	}
+
+	mapOfAddLabels := map[string]string{}
+	for l := range mapOfMatched {
+		if !HasLabel(pr.Labels, l) {
+			mapOfAddLabels[l] = l
+		}
+	}
+	mapOfRemoveLabels := map[string]string{}
+	if lr.RemoveUnmatched {
+		for l := range mapOfRuleLabels {
+			if _, ok := mapOfMatched[l]; !ok && HasLabel(pr.Labels, l) {
+				mapOfRemoveLabels[l] = l
+			}
+		}
+	}
+	if len(mapOfAddLabels) == 0 && len(mapOfRemoveLabels) == 0 {
+		return nil
+	}
+	glog.Infof("sync label rules. owner: %s repo: %s number: %d add: %v remove: %v",
+		owner, repo, pr.Number, mapOfAddLabels, mapOfRemoveLabels)
+
+	if lr.CreateLabels && len(mapOfAddLabels) > 0 {
+		err = s.CreateLabelsIfNotExist(owner, repo, mapOfAddLabels, lr.Color)
+		if err != nil {
+			return err
+		}
+	}
+
+	event := &gitee.NoteEvent{}
+	event.PullRequest = pr
+	event.Repository = repository
+	event.Comment = &gitee.Note{}
+	if len(mapOfRemoveLabels) == 0 {
+		return s.AddSpecifyLabelsInPulRequest(event, mapOfAddLabels)
+	}
+	return s.UpdateSpecifyLabelsInPulRequest(event, mapOfAddLabels, mapOfRemoveLabels)
+}
+
+// CreateLabelsIfNotExist creates the labels which are not existing in repository
+func (s *Server) CreateLabelsIfNotExist(owner, repo string, mapOfLabels map[string]string, color string) error {
+	lvosRepo := &gitee.GetV5ReposOwnerRepoLabelsOpts{}
+	lvosRepo.AccessToken = optional.NewString(s.Config.GiteeToken)
+	listofRepoLabels, _, err := s.GiteeClient.LabelsApi.GetV5ReposOwnerRepoLabels(s.Context, owner, repo, lvosRepo)
+	if err != nil {
+		glog.Errorf("unable to list repository labels. err: %v", err)
+		return err
+	}
+
+	// the color of label definition is used if no color is specified
+	mapOfColors := map[string]string{}
+	for _, def := range s.Config.LabelDefinitions.Labels {
+		mapOfColors[def.Name] = def.Color
+	}
+	for l := range mapOfLabels {
+		if HasLabel(listofRepoLabels, l) {
+			continue
+		}
+		labelColor := color
+		if labelColor == "" {
+			labelColor = mapOfColors[l]
+		}
+		// gitee requires the color without "#"
+		labelColor = strings.TrimPrefix(labelColor, "#")
+		if labelColor == "" {
+			labelColor = defaultLabelColor
+		}
+		localVarOptionals := &gitee.PostV5ReposOwnerRepoLabelsOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+		_, _, err = s.GiteeClient.LabelsApi.PostV5ReposOwnerRepoLabels(s.Context, owner, repo, l, labelColor, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to create label: %s owner: %s repo: %s err: %v", l, owner, repo, err)
+			return err
+		}
+		glog.Infof("create label successfully: %s owner: %s repo: %s", l, owner, repo)
+	}
+	return nil
+}
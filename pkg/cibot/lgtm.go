@@ -9,17 +9,6 @@ import (
 	"github.com/golang/glog"
 )
 
-const (
-	lgtmSelfOwnMessage         = `***lgtm*** can not be added in your self-own pull request. :astonished: `
-	lgtmAddedMessage           = `***lgtm*** is added in this pull request by: ***@%s***. :wave: `
-	lgtmRemovedMessage         = `***lgtm*** is removed in this pull request by: ***@%s***. :flushed: `
-	lgtmAddNoPermissionMessage = `***@%s*** has no permission to add ***lgtm*** in this pull request. :astonished:
-please contact to the collaborators in this repository.`
-	lgtmRemoveNoPermissionMessage = `***@%s*** has no permission to remove ***lgtm*** in this pull request. :astonished:
-please contact to the collaborators in this repository.`
-	lgtmRemovePullRequestChangeMessage = `new changes are detected. ***lgtm*** is removed in this pull request by: ***@%s***. :flushed: `
-)
-
 // AddLgtm adds lgtm label
 func (s *Server) AddLgtm(event *gitee.NoteEvent) error {
 	// handle PullRequest
@@ -42,7 +31,7 @@ func (s *Server) AddLgtm(event *gitee.NoteEvent) error {
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = lgtmSelfOwnMessage
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageLgtmSelfOwn, nil)
 				owner := event.Repository.Namespace
 				repo := event.Repository.Name
 				number := event.PullRequest.Number
@@ -82,7 +71,7 @@ func (s *Server) AddLgtm(event *gitee.NoteEvent) error {
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(lgtmAddedMessage, commentAuthor) + fmt.Sprintf(LabelHiddenValue, event.PullRequest.Head.Sha)
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageLgtmAdded, nil) + fmt.Sprintf(LabelHiddenValue, event.PullRequest.Head.Sha)
 				owner := event.Repository.Namespace
 				repo := event.Repository.Name
 				number := event.PullRequest.Number
@@ -100,7 +89,7 @@ func (s *Server) AddLgtm(event *gitee.NoteEvent) error {
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(lgtmAddNoPermissionMessage, commentAuthor)
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageLgtmAddNoPermission, nil)
 				owner := event.Repository.Namespace
 				repo := event.Repository.Name
 				number := event.PullRequest.Number
@@ -151,7 +140,7 @@ func (s *Server) RemoveLgtm(event *gitee.NoteEvent) error {
 					// add comment
 					body := gitee.PullRequestCommentPostParam{}
 					body.AccessToken = s.Config.GiteeToken
-					body.Body = fmt.Sprintf(lgtmRemoveNoPermissionMessage, commentAuthor)
+					body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageLgtmRemoveNoPermission, nil)
 					owner := event.Repository.Namespace
 					repo := event.Repository.Name
 					number := event.PullRequest.Number
@@ -179,7 +168,7 @@ func (s *Server) RemoveLgtm(event *gitee.NoteEvent) error {
 			// add comment
 			body := gitee.PullRequestCommentPostParam{}
 			body.AccessToken = s.Config.GiteeToken
-			body.Body = fmt.Sprintf(lgtmRemovedMessage, commentAuthor)
+			body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageLgtmRemoved, nil)
 			number := event.PullRequest.Number
 			_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, body)
 			if err != nil {
@@ -253,7 +242,7 @@ func (s *Server) CheckLgtmByPullRequestUpdate(event *gitee.PullRequestEvent) err
 				// add comment
 				body := gitee.PullRequestCommentPostParam{}
 				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(lgtmRemovePullRequestChangeMessage, s.Config.BotName)
+				body.Body = s.RenderMessage(owner, repo, event.PullRequest.User.Login, MessageLgtmRemovePullRequestChange, nil)
 				_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
 				if err != nil {
 					glog.Errorf("unable to add comment in pull request: %v", err)
@@ -0,0 +1,349 @@
+package cibot
+
+import (
+	"strings"
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/config"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	LabelNameLifecycleStale  = "lifecycle/stale"
+	LabelNameLifecycleRotten = "lifecycle/rotten"
+	LabelNameLifecycleFrozen = "lifecycle/frozen"
+	LabelPrefixLifecycle     = "lifecycle/"
+
+	// default duration of lifecycle in seconds
+	defaultLifecycleDuration = 3600
+	// default days of inactivity
+	defaultLifecycleStaleDays  = 90
+	defaultLifecycleRottenDays = 30
+	defaultLifecycleCloseDays  = 30
+)
+
+// LifecycleHandler marks the inactive pull requests and issues as stale and rotten, and closes them at last
+type LifecycleHandler struct {
+	Server
+}
+
+// GetLifecycle returns the lifecycle config of repository, nil if it is not enabled
+// the config of "owner/repo" is preferred to the config of "owner"
+func (s *Server) GetLifecycle(owner, repo string) *config.Lifecycle {
+	i := FindRepositoryConfig(len(s.Config.Lifecycles), func(i int) []string {
+		return s.Config.Lifecycles[i].Repositories
+	}, owner, repo)
+	if i < 0 {
+		return nil
+	}
+	return &s.Config.Lifecycles[i]
+}
+
+// getNoteableKind returns the kind of item in note event which is used in messages
+func getNoteableKind(event *gitee.NoteEvent) string {
+	if *event.NoteableType == "PullRequest" {
+		return "pull request"
+	}
+	return "issue"
+}
+
+// getNoteableAuthor returns the author of item in note event, who receives the lifecycle messages
+func getNoteableAuthor(event *gitee.NoteEvent) string {
+	if *event.NoteableType == "PullRequest" && event.PullRequest != nil && event.PullRequest.User != nil {
+		return event.PullRequest.User.Login
+	} else if *event.NoteableType == "Issue" && event.Issue != nil && event.Issue.User != nil {
+		return event.Issue.User.Login
+	}
+	return ""
+}
+
+// getNoteableLabels returns the labels of open item in note event
+func getNoteableLabels(event *gitee.NoteEvent) ([]gitee.Label, bool) {
+	if *event.NoteableType == "PullRequest" && event.PullRequest != nil {
+		return event.PullRequest.Labels, event.PullRequest.State == "open"
+	} else if *event.NoteableType == "Issue" && event.Issue != nil {
+		return event.Issue.Labels, event.Issue.State == "open"
+	}
+	return nil, false
+}
+
+// ApplyLifecycleLabels creates and adds the lifecycle labels, and removes the other lifecycle labels
+// it returns false if the labels are not applied, so the message is not commented again in every round
+func (s *Server) ApplyLifecycleLabels(event *gitee.NoteEvent, mapOfAddLabels, mapOfRemoveLabels map[string]string) (bool, error) {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	err := s.CreateLabelsIfNotExist(owner, repo, mapOfAddLabels, "")
+	if err != nil {
+		return false, err
+	}
+	err = s.UpdateSpecifyLabels(event, mapOfAddLabels, mapOfRemoveLabels)
+	if err != nil {
+		return false, err
+	}
+	applied, err := s.HasLabels(event, mapOfAddLabels)
+	if err != nil {
+		return false, err
+	}
+	if !applied {
+		glog.Errorf("lifecycle labels are not applied: %v owner: %s repo: %s", mapOfAddLabels, owner, repo)
+	}
+	return applied, nil
+}
+
+// SetLifecycle adds lifecycle label, and removes the other lifecycle labels
+func (s *Server) SetLifecycle(event *gitee.NoteEvent) error {
+	if _, isOpen := getNoteableLabels(event); !isOpen {
+		return nil
+	}
+	// get basic params
+	comment := event.Comment.Body
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	commentAuthor := event.Comment.User.Login
+	glog.Infof("set lifecycle started. comment: %s commentAuthor: %s owner: %s repo: %s",
+		comment, commentAuthor, owner, repo)
+
+	m := RegLifecycle.FindStringSubmatch(comment)
+	if m == nil {
+		return nil
+	}
+	label := LabelPrefixLifecycle + strings.ToLower(m[1])
+
+	mapOfAddLabels := map[string]string{label: label}
+	mapOfRemoveLabels := map[string]string{}
+	for _, l := range []string{LabelNameLifecycleStale, LabelNameLifecycleRotten, LabelNameLifecycleFrozen} {
+		if l != label {
+			mapOfRemoveLabels[l] = l
+		}
+	}
+	applied, err := s.ApplyLifecycleLabels(event, mapOfAddLabels, mapOfRemoveLabels)
+	if err != nil || !applied {
+		return err
+	}
+	return s.AddCommentInNoteEvent(event, s.RenderMessage(owner, repo, commentAuthor, MessageLifecycleAdded, MessageData{
+		"Label":         label,
+		"IsPullRequest": *event.NoteableType == "PullRequest",
+	}))
+}
+
+// RemoveLifecycle removes lifecycle label
+func (s *Server) RemoveLifecycle(event *gitee.NoteEvent) error {
+	if _, isOpen := getNoteableLabels(event); !isOpen {
+		return nil
+	}
+	// get basic params
+	comment := event.Comment.Body
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	commentAuthor := event.Comment.User.Login
+	glog.Infof("remove lifecycle started. comment: %s commentAuthor: %s owner: %s repo: %s",
+		comment, commentAuthor, owner, repo)
+
+	m := RegRemoveLifecycle.FindStringSubmatch(comment)
+	if m == nil {
+		return nil
+	}
+	label := LabelPrefixLifecycle + strings.ToLower(m[1])
+
+	err := s.UpdateSpecifyLabels(event, map[string]string{}, map[string]string{label: label})
+	if err != nil {
+		return err
+	}
+	return s.AddCommentInNoteEvent(event, s.RenderMessage(owner, repo, commentAuthor, MessageLifecycleRemoved, MessageData{
+		"Label":         label,
+		"IsPullRequest": *event.NoteableType == "PullRequest",
+	}))
+}
+
+// RemoveLifecycleByComment removes stale and rotten labels when someone comments
+func (s *Server) RemoveLifecycleByComment(event *gitee.NoteEvent) error {
+	if event.Comment.User == nil || event.Comment.User.Login == s.Config.BotName {
+		return nil
+	}
+	labels, isOpen := getNoteableLabels(event)
+	if !isOpen {
+		return nil
+	}
+	if !HasLabel(labels, LabelNameLifecycleStale) && !HasLabel(labels, LabelNameLifecycleRotten) {
+		return nil
+	}
+	glog.Infof("remove lifecycle by comment. commentAuthor: %s owner: %s repo: %s",
+		event.Comment.User.Login, event.Repository.Namespace, event.Repository.Name)
+
+	mapOfRemoveLabels := map[string]string{
+		LabelNameLifecycleStale:  LabelNameLifecycleStale,
+		LabelNameLifecycleRotten: LabelNameLifecycleRotten,
+	}
+	return s.UpdateSpecifyLabels(event, map[string]string{}, mapOfRemoveLabels)
+}
+
+// Serve checks the lifecycle of pull requests and issues periodically
+func (handler *LifecycleHandler) Serve() {
+	if len(handler.Config.Lifecycles) == 0 {
+		return
+	}
+
+	for {
+		duration := handler.Config.LifecycleDuration
+		if duration <= 0 {
+			duration = defaultLifecycleDuration
+		}
+		glog.Info("begin to check lifecycle")
+		handler.sync()
+		glog.Info("end to check lifecycle")
+		time.Sleep(time.Duration(duration) * time.Second)
+	}
+}
+
+// sync checks all open pull requests and issues in repositories
+func (handler *LifecycleHandler) sync() {
+	mapOfRepositories := map[string]bool{}
+	for _, lc := range handler.Config.Lifecycles {
+		for _, r := range handler.ListRepositories(lc.Repositories) {
+			key := r.Owner + "/" + r.Repo
+			if mapOfRepositories[key] {
+				continue
+			}
+			mapOfRepositories[key] = true
+			handler.syncRepository(r.Owner, r.Repo, handler.GetLifecycle(r.Owner, r.Repo))
+		}
+	}
+}
+
+// syncRepository checks all open pull requests and issues in repository
+func (handler *LifecycleHandler) syncRepository(owner, repo string, lc *config.Lifecycle) {
+	if lc == nil {
+		return
+	}
+	repository := &gitee.Project{}
+	repository.Namespace = owner
+	repository.Name = repo
+
+	prs, err := handler.ListPullRequests(owner, repo, "open")
+	if err == nil {
+		for i := range prs {
+			updatedAt, err := time.Parse(time.RFC3339, prs[i].UpdatedAt)
+			if err != nil {
+				glog.Errorf("invalid updated time of pull request: %s err: %v", prs[i].UpdatedAt, err)
+				continue
+			}
+			noteableType := "PullRequest"
+			event := &gitee.NoteEvent{}
+			event.NoteableType = &noteableType
+			event.Repository = repository
+			event.PullRequest = &prs[i]
+			event.Comment = &gitee.Note{}
+			err = handler.checkLifecycle(event, prs[i].Labels, updatedAt, lc)
+			if err != nil {
+				glog.Errorf("unable to check lifecycle. owner: %s repo: %s number: %d err: %v",
+					owner, repo, prs[i].Number, err)
+			}
+		}
+	}
+
+	issues, err := handler.ListIssues(owner, repo, "open")
+	if err == nil {
+		for _, issue := range issues {
+			noteableType := "Issue"
+			event := &gitee.NoteEvent{}
+			event.NoteableType = &noteableType
+			event.Repository = repository
+			event.Issue = &gitee.Issue{
+				Number: issue.Number,
+				State:  issue.State,
+				User:   issue.User,
+				Labels: issue.Labels,
+			}
+			event.Comment = &gitee.Note{}
+			err = handler.checkLifecycle(event, issue.Labels, issue.UpdatedAt, lc)
+			if err != nil {
+				glog.Errorf("unable to check lifecycle. owner: %s repo: %s number: %s err: %v",
+					owner, repo, issue.Number, err)
+			}
+		}
+	}
+}
+
+// checkLifecycle marks the item as stale or rotten, or closes it by the days of inactivity
+func (handler *LifecycleHandler) checkLifecycle(event *gitee.NoteEvent, labels []gitee.Label, updatedAt time.Time, lc *config.Lifecycle) error {
+	if HasLabel(labels, LabelNameLifecycleFrozen) {
+		return nil
+	}
+	staleDays := lc.StaleDays
+	if staleDays <= 0 {
+		staleDays = defaultLifecycleStaleDays
+	}
+	rottenDays := lc.RottenDays
+	if rottenDays <= 0 {
+		rottenDays = defaultLifecycleRottenDays
+	}
+	closeDays := lc.CloseDays
+	if closeDays <= 0 {
+		closeDays = defaultLifecycleCloseDays
+	}
+
+	// the labels and comments of bot are activities too,
+	// so the inactive days are counted from the last step
+	inactiveDays := int(time.Since(updatedAt).Hours() / 24)
+	kind := getNoteableKind(event)
+	isPullRequest := *event.NoteableType == "PullRequest"
+	author := getNoteableAuthor(event)
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+
+	if HasLabel(labels, LabelNameLifecycleRotten) {
+		if inactiveDays < closeDays {
+			return nil
+		}
+		glog.Infof("close rotten %s. owner: %s repo: %s inactive days: %d", kind, owner, repo, inactiveDays)
+		err := handler.AddCommentInNoteEvent(event, handler.RenderMessage(owner, repo, author, MessageLifecycleClose, MessageData{
+			"IsPullRequest": isPullRequest,
+			"Days":          closeDays,
+		}))
+		if err != nil {
+			return err
+		}
+		if event.PullRequest != nil {
+			return handler.ClosePullRequest(owner, repo, event.PullRequest.Number)
+		}
+		return handler.CloseIssue(owner, repo, event.Issue.Number, labels)
+	}
+
+	if HasLabel(labels, LabelNameLifecycleStale) {
+		if inactiveDays < rottenDays {
+			return nil
+		}
+		glog.Infof("mark stale %s as rotten. owner: %s repo: %s inactive days: %d", kind, owner, repo, inactiveDays)
+		applied, err := handler.ApplyLifecycleLabels(event,
+			map[string]string{LabelNameLifecycleRotten: LabelNameLifecycleRotten},
+			map[string]string{LabelNameLifecycleStale: LabelNameLifecycleStale})
+		if err != nil || !applied {
+			return err
+		}
+		return handler.AddCommentInNoteEvent(event,
+			handler.RenderMessage(owner, repo, author, MessageLifecycleRotten, MessageData{
+				"IsPullRequest": isPullRequest,
+				"Days":          rottenDays,
+				"Label":         LabelNameLifecycleRotten,
+				"CloseDays":     closeDays,
+			}))
+	}
+
+	if inactiveDays < staleDays {
+		return nil
+	}
+	glog.Infof("mark %s as stale. owner: %s repo: %s inactive days: %d", kind, owner, repo, inactiveDays)
+	applied, err := handler.ApplyLifecycleLabels(event, map[string]string{LabelNameLifecycleStale: LabelNameLifecycleStale}, map[string]string{})
+	if err != nil || !applied {
+		return err
+	}
+	return handler.AddCommentInNoteEvent(event,
+		handler.RenderMessage(owner, repo, author, MessageLifecycleStale, MessageData{
+			"IsPullRequest": isPullRequest,
+			"Days":          staleDays,
+			"Label":         LabelNameLifecycleStale,
+			"RottenLabel":   LabelNameLifecycleRotten,
+			"RottenDays":    rottenDays,
+		}))
+}
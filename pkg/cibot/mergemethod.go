@@ -0,0 +1,142 @@
+package cibot
+
+import (
+	"fmt"
+	"strings"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+	// the label to override merge method. e.g merge/squash
+	LabelPrefixMergeMethod = "merge/"
+)
+
+var (
+	listOfMergeMethods = []string{MergeMethodMerge, MergeMethodSquash, MergeMethodRebase}
+)
+
+// isValidMergeMethod checks the merge method is supported
+func isValidMergeMethod(method string) bool {
+	for _, m := range listOfMergeMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMergeMethod returns the merge method of pull request
+// the order is: label in pull request > repository > organization > default
+func (s *Server) GetMergeMethod(owner, repo string, pr gitee.PullRequest) string {
+	// merge method in pull request label
+	for _, l := range pr.Labels {
+		if strings.HasPrefix(l.Name, LabelPrefixMergeMethod) {
+			method := strings.TrimPrefix(l.Name, LabelPrefixMergeMethod)
+			if isValidMergeMethod(method) {
+				return method
+			}
+		}
+	}
+	// merge method in repository or organization
+	for _, key := range []string{owner + "/" + repo, owner} {
+		if method, ok := s.Config.MergeMethods[key]; ok {
+			if isValidMergeMethod(method) {
+				return method
+			}
+			glog.Errorf("invalid merge method: %s for: %s", method, key)
+		}
+	}
+	// default merge method
+	if isValidMergeMethod(s.Config.MergeMethod) {
+		return s.Config.MergeMethod
+	}
+	return MergeMethodMerge
+}
+
+// SetMergeMethod sets merge method of pull request by label
+func (s *Server) SetMergeMethod(event *gitee.NoteEvent) error {
+	// handle PullRequest
+	if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			// get basic params
+			comment := event.Comment.Body
+			owner := event.Repository.Namespace
+			repo := event.Repository.Name
+			prNumber := event.PullRequest.Number
+			commentAuthor := event.Comment.User.Login
+			glog.Infof("set merge method started. comment: %s commentAuthor: %s owner: %s repo: %s number: %d",
+				comment, commentAuthor, owner, repo, prNumber)
+
+			// get the merge method from comment
+			method := ""
+			m := RegMergeMethod.FindStringSubmatch(comment)
+			if m != nil {
+				method = strings.ToLower(strings.TrimSpace(m[1]))
+			}
+
+			// build comment
+			body := gitee.PullRequestCommentPostParam{}
+			body.AccessToken = s.Config.GiteeToken
+			if !isValidMergeMethod(method) {
+				body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageMergeMethodInvalid, MessageData{"Method": method})
+			} else {
+				// check if current author can set merge method
+				hasPermission, err := s.CheckPullRequestPermission(event, commentAuthor)
+				if err != nil {
+					return err
+				}
+				if hasPermission {
+					// replace the merge method label
+					mapOfAddLabels := map[string]string{}
+					mapOfRemoveLabels := map[string]string{}
+					for _, name := range listOfMergeMethods {
+						label := LabelPrefixMergeMethod + name
+						if name == method {
+							mapOfAddLabels[label] = label
+						} else {
+							mapOfRemoveLabels[label] = label
+						}
+					}
+					updatelabel := &gitee.NoteEvent{}
+					updatelabel.PullRequest = event.PullRequest
+					updatelabel.Repository = event.Repository
+					updatelabel.Comment = &gitee.Note{}
+					err = s.CreateLabelsIfNotExist(owner, repo, mapOfAddLabels, "")
+					if err != nil {
+						return err
+					}
+					err = s.UpdateSpecifyLabelsInPulRequest(updatelabel, mapOfAddLabels, mapOfRemoveLabels)
+					if err != nil {
+						return err
+					}
+					// the merge method is only replied after the label is applied
+					applied, err := s.HasLabelsInPullRequest(owner, repo, prNumber, mapOfAddLabels)
+					if err != nil {
+						return err
+					}
+					if !applied {
+						glog.Errorf("merge method label is not applied. owner: %s repo: %s number: %d", owner, repo, prNumber)
+						return fmt.Errorf("merge method label is not applied in pull request %d", prNumber)
+					}
+					body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageMergeMethodSet, MessageData{"Method": method})
+				} else {
+					body.Body = s.RenderMessage(owner, repo, commentAuthor, MessageMergeMethodNoPermission, nil)
+				}
+			}
+
+			// add comment
+			_, _, err := s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, prNumber, body)
+			if err != nil {
+				glog.Errorf("unable to add comment in pull request: %v", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
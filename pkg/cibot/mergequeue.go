@@ -0,0 +1,180 @@
+package cibot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+const (
+	// default duration of merge queue in seconds
+	defaultMergeQueueDuration = 300
+)
+
+// MergeQueue merges the ready pull requests periodically
+type MergeQueue struct {
+	Server
+	mutex  sync.RWMutex
+	status MergeQueueStatus
+}
+
+// MergeQueueStatus defines the state of merge queue
+type MergeQueueStatus struct {
+	LastSync time.Time   `json:"lastSync"`
+	Pools    []MergePool `json:"pools"`
+}
+
+// MergePool defines the pull requests targeting the same branch
+type MergePool struct {
+	Owner   string               `json:"owner"`
+	Repo    string               `json:"repo"`
+	Branch  string               `json:"branch"`
+	Ready   []int32              `json:"ready"`
+	Pending []PendingPullRequest `json:"pending"`
+	Action  string               `json:"action,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// PendingPullRequest defines the pull request which is not ready to merge
+type PendingPullRequest struct {
+	Number   int32          `json:"number"`
+	Blockers []MergeBlocker `json:"blockers"`
+}
+
+// Serve syncs the merge queue periodically
+func (q *MergeQueue) Serve() {
+	if len(q.Config.MergeQueue.Repositories) == 0 {
+		return
+	}
+
+	for {
+		duration := q.Config.MergeQueue.Duration
+		if duration <= 0 {
+			duration = defaultMergeQueueDuration
+		}
+		glog.Info("begin to sync merge queue")
+		q.sync()
+		glog.Info("end to sync merge queue")
+		time.Sleep(time.Duration(duration) * time.Second)
+	}
+}
+
+// sync merges one ready pull request per branch in each repository
+func (q *MergeQueue) sync() {
+	pools := make([]MergePool, 0)
+	for _, r := range q.ListRepositories(q.Config.MergeQueue.Repositories) {
+		prs, err := q.ListPullRequests(r.Owner, r.Repo, "open")
+		if err != nil {
+			pools = append(pools, MergePool{Owner: r.Owner, Repo: r.Repo, Error: err.Error()})
+			continue
+		}
+
+		// group pull requests by target branch
+		mapOfPools := map[string]*MergePool{}
+		branches := make([]string, 0)
+		for _, pr := range prs {
+			branch := ""
+			if pr.Base != nil {
+				branch = pr.Base.Ref
+			}
+			pool, ok := mapOfPools[branch]
+			if !ok {
+				pool = &MergePool{
+					Owner:   r.Owner,
+					Repo:    r.Repo,
+					Branch:  branch,
+					Ready:   make([]int32, 0),
+					Pending: make([]PendingPullRequest, 0),
+				}
+				mapOfPools[branch] = pool
+				branches = append(branches, branch)
+			}
+			listOfMergeBlockers := q.GetListOfMergeBlockers(r.Owner, r.Repo, pr)
+			if len(listOfMergeBlockers) > 0 {
+				pool.Pending = append(pool.Pending, PendingPullRequest{Number: pr.Number, Blockers: listOfMergeBlockers})
+			} else {
+				pool.Ready = append(pool.Ready, pr.Number)
+			}
+		}
+
+		sort.Strings(branches)
+		for _, branch := range branches {
+			pool := mapOfPools[branch]
+			// the oldest pull request goes first
+			sort.Slice(pool.Ready, func(i, j int) bool { return pool.Ready[i] < pool.Ready[j] })
+			if len(pool.Ready) > 0 {
+				q.mergeOne(pool)
+			}
+			pools = append(pools, *pool)
+		}
+	}
+
+	// update status
+	q.mutex.Lock()
+	q.status = MergeQueueStatus{
+		LastSync: time.Now(),
+		Pools:    pools,
+	}
+	q.mutex.Unlock()
+}
+
+// mergeOne merges the first ready pull request in pool
+func (q *MergeQueue) mergeOne(pool *MergePool) {
+	number := pool.Ready[0]
+
+	// the list may be outdated, so check the pull request again
+	lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
+	lvos.AccessToken = optional.NewString(q.Config.GiteeToken)
+	pr, _, err := q.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumber(q.Context, pool.Owner, pool.Repo, number, lvos)
+	if err != nil {
+		glog.Errorf("unable to get pull request. err: %v", err)
+		pool.Error = err.Error()
+		return
+	}
+	repository := &gitee.Project{}
+	repository.Namespace = pool.Owner
+	repository.Name = pool.Repo
+	// the pull requests created before opting in have no release note labels
+	err = q.SyncReleaseNoteLabel(repository, &pr)
+	if err != nil {
+		glog.Errorf("unable to sync release note label. err: %v", err)
+	}
+	listOfMergeBlockers := q.GetListOfMergeBlockers(pool.Owner, pool.Repo, pr)
+	if len(listOfMergeBlockers) > 0 {
+		glog.Infof("pull request is not ready to merge any more: %d blockers: %v", number, listOfMergeBlockers)
+		pool.Ready = pool.Ready[1:]
+		pool.Pending = append(pool.Pending, PendingPullRequest{Number: number, Blockers: listOfMergeBlockers})
+		return
+	}
+
+	err = q.MergeReadyPullRequest(repository, pr)
+	if err != nil {
+		pool.Error = err.Error()
+		return
+	}
+	pool.Ready = pool.Ready[1:]
+	pool.Action = fmt.Sprintf("merged: %d", number)
+}
+
+// ServeHTTP outputs the state of merge queue
+func (q *MergeQueue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q.mutex.RLock()
+	result, err := json.Marshal(q.status)
+	q.mutex.RUnlock()
+	if err != nil {
+		glog.Errorf("marshal merge queue status error: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(result)
+}
@@ -0,0 +1,112 @@
+package cibot
+
+import (
+	"gitee.com/openeuler/ci-bot/pkg/cibot/config"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// the types of merge blockers, which are explained by the merge-blocked message
+	mergeBlockerTypeMissingLabel  = "missing-label"
+	mergeBlockerTypeBlockingLabel = "blocking-label"
+	mergeBlockerTypeHold          = "hold"
+	mergeBlockerTypeCLA           = "cla"
+	mergeBlockerTypeReleaseNote   = "release-note"
+	mergeBlockerTypeDCO           = "dco"
+	mergeBlockerTypeCommitMessage = "commit-message"
+	mergeBlockerTypeCIPending     = "ci-pending"
+	mergeBlockerTypeCIFailed      = "ci-failed"
+	mergeBlockerTypeConflicts     = "conflicts"
+)
+
+// MergeBlocker defines the reason why the pull request can not be merged
+type MergeBlocker struct {
+	Type  string `json:"type"`
+	Label string `json:"label,omitempty"`
+}
+
+// getPullRequestAuthor returns the login of pull request author
+func getPullRequestAuthor(pr gitee.PullRequest) string {
+	if pr.User == nil {
+		return ""
+	}
+	return pr.User.Login
+}
+
+// SyncRebaseLabel adds needs-rebase label if the pull request is not mergeable, otherwise removes it
+// the labels of pull request are updated after syncing
+func (s *Server) SyncRebaseLabel(repository *gitee.Project, pr *gitee.PullRequest) error {
+	hasRebase := HasLabel(pr.Labels, LabelNameRebase)
+	glog.Infof("sync rebase label. number: %d mergeable: %t has rebase label: %t", pr.Number, pr.Mergeable, hasRebase)
+
+	event := &gitee.NoteEvent{}
+	event.PullRequest = pr
+	event.Repository = repository
+	event.Comment = &gitee.Note{}
+	mapOfLabels := map[string]string{}
+	mapOfLabels[LabelNameRebase] = LabelNameRebase
+	if !pr.Mergeable && !hasRebase {
+		err := s.CreateLabelsIfNotExist(repository.Namespace, repository.Name, mapOfLabels, "")
+		if err != nil {
+			return err
+		}
+		err = s.AddSpecifyLabelsInPulRequest(event, mapOfLabels)
+		if err != nil {
+			return err
+		}
+		pr.Labels = append(pr.Labels, gitee.Label{Name: LabelNameRebase})
+	}
+	if pr.Mergeable && hasRebase {
+		err := s.RemoveSpecifyLabelsInPulRequest(event, mapOfLabels)
+		if err != nil {
+			return err
+		}
+		listofLabels := make([]gitee.Label, 0)
+		for _, l := range pr.Labels {
+			if l.Name != LabelNameRebase {
+				listofLabels = append(listofLabels, l)
+			}
+		}
+		pr.Labels = listofLabels
+	}
+	return nil
+}
+
+// UpdateMergeStatusComment updates the merge status comment of bot, or adds it if not existing
+func (s *Server) UpdateMergeStatusComment(owner, repo string, number int32, message string) error {
+	return s.UpdateStickyCommentInPullRequest(owner, repo, number, StickyCommentKindMergeStatus, message)
+}
+
+// UpdateExistingMergeStatusComment updates the merge status comment of bot only if it exists
+// so the reported blockers are not left in pull request after they are resolved
+func (s *Server) UpdateExistingMergeStatusComment(owner, repo string, number int32, message string) error {
+	return s.UpdateExistingStickyCommentInPullRequest(owner, repo, number, StickyCommentKindMergeStatus, message)
+}
+
+// GetCICheck returns the ci check of repository, nil if it is not enabled
+// the check of "owner/repo" is preferred to the check of "owner"
+func (s *Server) GetCICheck(owner, repo string) *config.CICheck {
+	i := FindRepositoryConfig(len(s.Config.CIChecks), func(i int) []string {
+		return s.Config.CIChecks[i].Repositories
+	}, owner, repo)
+	if i < 0 {
+		return nil
+	}
+	return &s.Config.CIChecks[i]
+}
+
+// GetListOfCIBlockers returns the reasons why the ci blocks the merge
+func (s *Server) GetListOfCIBlockers(owner, repo string, pr gitee.PullRequest) []MergeBlocker {
+	listOfCIBlockers := make([]MergeBlocker, 0)
+	cc := s.GetCICheck(owner, repo)
+	if cc == nil {
+		return listOfCIBlockers
+	}
+	if cc.FailureLabel != "" && HasLabel(pr.Labels, cc.FailureLabel) {
+		listOfCIBlockers = append(listOfCIBlockers, MergeBlocker{Type: mergeBlockerTypeCIFailed})
+	} else if cc.SuccessLabel != "" && !HasLabel(pr.Labels, cc.SuccessLabel) {
+		listOfCIBlockers = append(listOfCIBlockers, MergeBlocker{Type: mergeBlockerTypeCIPending, Label: cc.SuccessLabel})
+	}
+	return listOfCIBlockers
+}
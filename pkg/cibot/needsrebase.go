@@ -0,0 +1,203 @@
+package cibot
+
+import (
+	"sort"
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// the name of needs rebase job in job progresses
+	jobNameNeedsRebase = "needs-rebase"
+	// default duration of needs rebase job in seconds
+	defaultNeedsRebaseDuration = 600
+	// default interval between two pull requests in milliseconds
+	defaultNeedsRebaseInterval = 1000
+)
+
+// NeedsRebaseHandler checks the mergeability of open pull requests periodically
+type NeedsRebaseHandler struct {
+	Server
+}
+
+// Serve checks the open pull requests periodically
+func (handler *NeedsRebaseHandler) Serve() {
+	if len(handler.Config.NeedsRebase.Repositories) == 0 {
+		return
+	}
+
+	for {
+		duration := handler.Config.NeedsRebase.Duration
+		if duration <= 0 {
+			duration = defaultNeedsRebaseDuration
+		}
+		glog.Info("begin to check needs rebase")
+		handler.sync()
+		glog.Info("end to check needs rebase")
+		time.Sleep(time.Duration(duration) * time.Second)
+	}
+}
+
+// sync checks all open pull requests, and resumes from the last handled one
+func (handler *NeedsRebaseHandler) sync() {
+	interval := handler.Config.NeedsRebase.Interval
+	if interval <= 0 {
+		interval = defaultNeedsRebaseInterval
+	}
+
+	// get the progress of last round
+	var jps []database.JobProgresses
+	err := database.DBConnection.Model(&database.JobProgresses{}).
+		Where("name = ?", jobNameNeedsRebase).Find(&jps).Error
+	if err != nil {
+		glog.Errorf("unable to get job progresses: %v", err)
+		return
+	}
+	progress := database.JobProgresses{Name: jobNameNeedsRebase}
+	if len(jps) > 0 {
+		progress = jps[0]
+	}
+
+	repositories := handler.ListRepositories(handler.Config.NeedsRebase.Repositories)
+	// skip the repositories handled in last round
+	if progress.Owner != "" {
+		for i, r := range repositories {
+			if r.Owner == progress.Owner && r.Repo == progress.Repo {
+				glog.Infof("resume needs rebase job. owner: %s repo: %s number: %d",
+					progress.Owner, progress.Repo, progress.Number)
+				repositories = repositories[i:]
+				break
+			}
+		}
+	}
+
+	for _, r := range repositories {
+		prs, err := handler.ListPullRequests(r.Owner, r.Repo, "open")
+		if err != nil {
+			continue
+		}
+		sort.Slice(prs, func(i, j int) bool { return prs[i].Number < prs[j].Number })
+
+		resumed := r.Owner == progress.Owner && r.Repo == progress.Repo
+		mapOfNumbers := map[int32]bool{}
+		for i := range prs {
+			mapOfNumbers[prs[i].Number] = true
+			// skip the pull requests handled in last round
+			if resumed && prs[i].Number <= progress.Number {
+				continue
+			}
+
+			err = handler.checkPullRequest(r.Owner, r.Repo, &prs[i])
+			if err != nil {
+				glog.Errorf("unable to check needs rebase. owner: %s repo: %s number: %d err: %v",
+					r.Owner, r.Repo, prs[i].Number, err)
+			}
+
+			// save progress
+			progress.Owner = r.Owner
+			progress.Repo = r.Repo
+			progress.Number = prs[i].Number
+			err = database.DBConnection.Save(&progress).Error
+			if err != nil {
+				glog.Errorf("unable to save job progress: %v", err)
+			}
+
+			time.Sleep(time.Duration(interval) * time.Millisecond)
+		}
+
+		handler.cleanNotifications(r.Owner, r.Repo, mapOfNumbers)
+	}
+
+	// the round is finished, so start from beginning in next round
+	progress.Owner = ""
+	progress.Repo = ""
+	progress.Number = 0
+	err = database.DBConnection.Save(&progress).Error
+	if err != nil {
+		glog.Errorf("unable to save job progress: %v", err)
+	}
+}
+
+// checkPullRequest syncs needs rebase label and notifies author once when rebase is needed
+func (handler *NeedsRebaseHandler) checkPullRequest(owner, repo string, pr *gitee.PullRequest) error {
+	repository := &gitee.Project{}
+	repository.Namespace = owner
+	repository.Name = repo
+	err := handler.SyncRebaseLabel(repository, pr)
+	if err != nil {
+		return err
+	}
+
+	if pr.Mergeable {
+		// reset notification, so the author will be notified when conflicts appear again
+		err = database.DBConnection.
+			Where("owner = ? and repo = ? and number = ?", owner, repo, pr.Number).
+			Delete(&database.RebaseNotifications{}).Error
+		if err != nil {
+			glog.Errorf("unable to delete rebase notifications: %v", err)
+		}
+		return err
+	}
+
+	// check if the author is already notified
+	var lenNotifications int
+	err = database.DBConnection.Model(&database.RebaseNotifications{}).
+		Where("owner = ? and repo = ? and number = ?", owner, repo, pr.Number).
+		Count(&lenNotifications).Error
+	if err != nil {
+		glog.Errorf("unable to get rebase notifications: %v", err)
+		return err
+	}
+	if lenNotifications > 0 {
+		return nil
+	}
+
+	prAuthor := ""
+	if pr.User != nil {
+		prAuthor = pr.User.Login
+	}
+	glog.Infof("notify author to rebase. owner: %s repo: %s number: %d author: %s", owner, repo, pr.Number, prAuthor)
+	// add comment
+	body := gitee.PullRequestCommentPostParam{}
+	body.AccessToken = handler.Config.GiteeToken
+	body.Body = handler.RenderMessage(owner, repo, prAuthor, MessageNeedsRebase, MessageData{"Label": LabelNameRebase})
+	_, _, err = handler.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(handler.Context, owner, repo, pr.Number, body)
+	if err != nil {
+		glog.Errorf("unable to add comment in pull request: %v", err)
+		return err
+	}
+
+	// record notification
+	addrn := database.RebaseNotifications{
+		Owner:  owner,
+		Repo:   repo,
+		Number: pr.Number,
+	}
+	err = database.DBConnection.Create(&addrn).Error
+	if err != nil {
+		glog.Errorf("unable to add rebase notifications: %v", err)
+	}
+	return err
+}
+
+// cleanNotifications deletes the notifications of pull requests which are not open any more
+func (handler *NeedsRebaseHandler) cleanNotifications(owner, repo string, mapOfNumbers map[int32]bool) {
+	var rns []database.RebaseNotifications
+	err := database.DBConnection.Model(&database.RebaseNotifications{}).
+		Where("owner = ? and repo = ?", owner, repo).Find(&rns).Error
+	if err != nil {
+		glog.Errorf("unable to get rebase notifications: %v", err)
+		return
+	}
+	for i := range rns {
+		if !mapOfNumbers[rns[i].Number] {
+			err = database.DBConnection.Delete(&rns[i]).Error
+			if err != nil {
+				glog.Errorf("unable to delete rebase notifications: %v", err)
+			}
+		}
+	}
+}
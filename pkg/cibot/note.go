@@ -1,6 +1,8 @@
 package cibot
 
 import (
+	"fmt"
+
 	"gitee.com/openeuler/go-gitee/gitee"
 	"github.com/golang/glog"
 )
@@ -10,13 +12,27 @@ func (s *Server) HandleNoteEvent(event *gitee.NoteEvent) {
 	if event == nil {
 		return
 	}
-	// just handle create comment event
-	if *event.Action != "comment" {
+	// handle created comment, and the new commands in edited comment
+	// the commands in quoted text and code are ignored
+	if !s.PrepareNoteEvent(event) {
+		return
+	}
+
+	// ignore the commands of blocked users and spam
+	if !s.CheckRateLimit(event) {
 		return
 	}
 
+	// remove stale and rotten lifecycle when someone comments
+	if !RegLifecycle.MatchString(event.Comment.Body) && !RegRemoveLifecycle.MatchString(event.Comment.Body) {
+		err := s.RemoveLifecycleByComment(event)
+		if err != nil {
+			glog.Errorf("failed to remove lifecycle by comment: %v", err)
+		}
+	}
+
 	// add label
-	if RegAddLabel.MatchString(event.Comment.Body) {
+	if RegLabel.MatchString(event.Comment.Body) || s.GetRegAddLabel().MatchString(event.Comment.Body) {
 		err := s.AddLabel(event)
 		if err != nil {
 			glog.Errorf("failed to add label: %v", err)
@@ -24,7 +40,7 @@ func (s *Server) HandleNoteEvent(event *gitee.NoteEvent) {
 	}
 
 	// remove label
-	if RegRemoveLabel.MatchString(event.Comment.Body) {
+	if RegRemoveLabel.MatchString(event.Comment.Body) || s.GetRegRemoveLabel().MatchString(event.Comment.Body) {
 		err := s.RemoveLabel(event)
 		if err != nil {
 			glog.Errorf("failed to remove label: %v", err)
@@ -39,6 +55,14 @@ func (s *Server) HandleNoteEvent(event *gitee.NoteEvent) {
 		}
 	}
 
+	// check dco and commit messages by note event
+	if RegCheckDCO.MatchString(event.Comment.Body) {
+		err := s.CheckCommitsByNoteEvent(event)
+		if err != nil {
+			glog.Errorf("failed to check commits by note event: %v", err)
+		}
+	}
+
 	// add lgtm
 	if RegAddLgtm.MatchString(event.Comment.Body) {
 		err := s.AddLgtm(event)
@@ -71,6 +95,30 @@ func (s *Server) HandleNoteEvent(event *gitee.NoteEvent) {
 		}
 	}
 
+	// add hold
+	if RegHold.MatchString(event.Comment.Body) {
+		err := s.AddHold(event)
+		if err != nil {
+			glog.Errorf("failed to add hold: %v", err)
+		}
+	}
+
+	// remove hold
+	if RegHoldCancel.MatchString(event.Comment.Body) {
+		err := s.RemoveHold(event)
+		if err != nil {
+			glog.Errorf("failed to remove hold: %v", err)
+		}
+	}
+
+	// merge method
+	if RegMergeMethod.MatchString(event.Comment.Body) {
+		err := s.SetMergeMethod(event)
+		if err != nil {
+			glog.Errorf("failed to set merge method: %v", err)
+		}
+	}
+
 	// close
 	if RegClose.MatchString(event.Comment.Body) {
 		err := s.Close(event)
@@ -102,4 +150,72 @@ func (s *Server) HandleNoteEvent(event *gitee.NoteEvent) {
 			glog.Errorf("failed to unassign: %v", err)
 		}
 	}
+
+	// cc
+	if RegCC.MatchString(event.Comment.Body) {
+		err := s.CC(event)
+		if err != nil {
+			glog.Errorf("failed to cc: %v", err)
+		}
+	}
+
+	// uncc
+	if RegUnCC.MatchString(event.Comment.Body) {
+		err := s.UnCC(event)
+		if err != nil {
+			glog.Errorf("failed to uncc: %v", err)
+		}
+	}
+
+	// lifecycle
+	if RegLifecycle.MatchString(event.Comment.Body) {
+		err := s.SetLifecycle(event)
+		if err != nil {
+			glog.Errorf("failed to set lifecycle: %v", err)
+		}
+	}
+
+	// remove lifecycle
+	if RegRemoveLifecycle.MatchString(event.Comment.Body) {
+		err := s.RemoveLifecycle(event)
+		if err != nil {
+			glog.Errorf("failed to remove lifecycle: %v", err)
+		}
+	}
+
+	// release note
+	if RegReleaseNote.MatchString(event.Comment.Body) || RegReleaseNoteNone.MatchString(event.Comment.Body) {
+		err := s.SetReleaseNote(event)
+		if err != nil {
+			glog.Errorf("failed to set release note: %v", err)
+		}
+	}
+}
+
+// AddCommentInNoteEvent adds comment in the pull request or issue of note event
+func (s *Server) AddCommentInNoteEvent(event *gitee.NoteEvent, comment string) error {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	if *event.NoteableType == "PullRequest" {
+		body := gitee.PullRequestCommentPostParam{}
+		body.AccessToken = s.Config.GiteeToken
+		body.Body = comment
+		_, _, err := s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, event.PullRequest.Number, body)
+		if err != nil {
+			glog.Errorf("unable to add comment in pull request: %v", err)
+			return err
+		}
+	} else if *event.NoteableType == "Issue" {
+		body := gitee.IssueCommentPostParam{}
+		body.AccessToken = s.Config.GiteeToken
+		body.Body = comment
+		_, _, err := s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, event.Issue.Number, body)
+		if err != nil {
+			glog.Errorf("unable to add comment in issue: %v", err)
+			return err
+		}
+	} else {
+		return fmt.Errorf("unsupported noteable type: %s", *event.NoteableType)
+	}
+	return nil
 }
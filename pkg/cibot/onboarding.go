@@ -0,0 +1,247 @@
+package cibot
+
+import (
+	"fmt"
+	"net/url"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+const (
+	// LabelNameFirstContribution is added in the pull requests and issues of first-time contributors
+	LabelNameFirstContribution = "first-contribution"
+
+	// the scopes of first-time contributors
+	onboardingScopeRepository   = "repository"
+	onboardingScopeOrganization = "organization"
+)
+
+// isContributor checks the user has opened pull requests or issues in repository,
+// or in any repository of organization if the scope is organization
+func (s *Server) isContributor(owner, repo, login string) (bool, error) {
+	var lenContributors int
+	query := database.DBConnection.Model(&database.Contributors{}).Where("owner = ? and login = ?", owner, login)
+	if s.Config.Onboarding.Scope != onboardingScopeOrganization {
+		query = query.Where("repo = ?", repo)
+	}
+	err := query.Count(&lenContributors).Error
+	if err != nil {
+		glog.Errorf("unable to get contributors: %v", err)
+		return false, err
+	}
+	return lenContributors > 0, nil
+}
+
+// isCollaborator checks the user has write permission in repository
+// the collaborators are regular contributors even if they are not recorded
+func (s *Server) isCollaborator(owner, repo, login string) bool {
+	localVarOptionals := &gitee.GetV5ReposOwnerRepoCollaboratorsUsernamePermissionOpts{}
+	localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+	permission, _, err := s.GiteeClient.RepositoriesApi.GetV5ReposOwnerRepoCollaboratorsUsernamePermission(
+		s.Context, owner, repo, login, localVarOptionals)
+	if err != nil {
+		glog.Errorf("unable to get user permission: %v", err)
+		return false
+	}
+	// permission: admin, write, read, none
+	return permission.Permission == "admin" || permission.Permission == "write"
+}
+
+// hasGiteeHistory checks the user has opened other pull requests or issues in repository on gitee,
+// so the contributions before the bot is deployed are not missed.
+// gitee has no api to list the pull requests of user in organization, so only the repository is checked
+func (s *Server) hasGiteeHistory(owner, repo, login string) (bool, error) {
+	// the current pull request or issue is listed too, so two items are enough
+	query := url.Values{}
+	query.Set("state", "all")
+	query.Set("author", login)
+	query.Set("per_page", "2")
+	var prs []gitee.PullRequest
+	err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/pulls", owner, repo), query, nil, &prs)
+	if err != nil {
+		glog.Errorf("unable to list pull requests of user. owner: %s repo: %s login: %s err: %v", owner, repo, login, err)
+		return false, err
+	}
+	query = url.Values{}
+	query.Set("state", "all")
+	query.Set("creator", login)
+	query.Set("per_page", "2")
+	var issues []IssueItem
+	err = s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/issues", owner, repo), query, nil, &issues)
+	if err != nil {
+		glog.Errorf("unable to list issues of user. owner: %s repo: %s login: %s err: %v", owner, repo, login, err)
+		return false, err
+	}
+
+	// check the authors again in case the filters are ignored by gitee
+	count := 0
+	for _, pr := range prs {
+		if pr.User != nil && pr.User.Login == login {
+			count++
+		}
+	}
+	for _, issue := range issues {
+		if issue.User != nil && issue.User.Login == login {
+			count++
+		}
+	}
+	return count > 1, nil
+}
+
+// addContributor records the user as a contributor of repository
+func addContributor(owner, repo, login string) error {
+	var lenContributors int
+	err := database.DBConnection.Model(&database.Contributors{}).
+		Where("owner = ? and repo = ? and login = ?", owner, repo, login).Count(&lenContributors).Error
+	if err != nil {
+		glog.Errorf("unable to get contributors: %v", err)
+		return err
+	}
+	if lenContributors > 0 {
+		return nil
+	}
+	addc := database.Contributors{
+		Owner: owner,
+		Repo:  repo,
+		Login: login,
+	}
+	err = database.DBConnection.Create(&addc).Error
+	if err != nil {
+		glog.Errorf("unable to add contributor: %v", err)
+	}
+	return err
+}
+
+// IsFirstContribution checks the user contributes to repository or organization for the first time,
+// and records the user as a contributor of repository
+func (s *Server) IsFirstContribution(owner, repo, login string) bool {
+	contributed, err := s.isContributor(owner, repo, login)
+	if err != nil {
+		// not sure, so treat the user as a regular contributor
+		return false
+	}
+	if !contributed {
+		contributed = s.isCollaborator(owner, repo, login)
+	}
+	// the history on gitee is of repository only, which can not tell the first contribution to organization
+	if !contributed && s.Config.Onboarding.Scope != onboardingScopeOrganization {
+		contributed, err = s.hasGiteeHistory(owner, repo, login)
+		if err != nil {
+			// not sure, so treat the user as a regular contributor
+			contributed = true
+		}
+	}
+	err = addContributor(owner, repo, login)
+	if err != nil {
+		glog.Errorf("failed to record contributor: %v", err)
+	}
+	return !contributed
+}
+
+// GetWelcomeMessage returns the onboarding message for first-time contributors, or the short welcome for others
+// the message is empty if the regular contributors are not welcomed
+func (s *Server) GetWelcomeMessage(owner, repo, login string, first bool, data MessageData) string {
+	if first {
+		fullData := MessageData{
+			"IsPullRequest":    false,
+			"ClaSigned":        false,
+			"ClaUnknown":       false,
+			"ContributingLink": s.Config.Onboarding.ContributingLink,
+		}
+		for k, v := range data {
+			fullData[k] = v
+		}
+		return s.RenderMessage(owner, repo, login, MessageOnboarding, fullData)
+	}
+	if s.Config.Onboarding.SkipRegularWelcome {
+		return ""
+	}
+	return s.RenderMessage(owner, repo, login, MessageWelcome, nil)
+}
+
+// WelcomeInPullRequest welcomes the author of pull request, and adds first-contribution label for first-time contributors
+// claKnown and claSigned tell the onboarding message whether the commits of pull request have signed the cla,
+// the cla is unknown if the commits can not be checked
+func (s *Server) WelcomeInPullRequest(event *gitee.PullRequestEvent, claKnown, claSigned bool) error {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	login := event.PullRequest.User.Login
+	first := s.IsFirstContribution(owner, repo, login)
+	glog.Infof("welcome in pull request. owner: %s repo: %s login: %s first: %v", owner, repo, login, first)
+
+	if first {
+		mapOfLabels := map[string]string{LabelNameFirstContribution: LabelNameFirstContribution}
+		err := s.CreateLabelsIfNotExist(owner, repo, mapOfLabels, "")
+		if err != nil {
+			return err
+		}
+		labelEvent := &gitee.NoteEvent{}
+		labelEvent.PullRequest = event.PullRequest
+		labelEvent.Repository = event.Repository
+		labelEvent.Comment = &gitee.Note{}
+		err = s.AddSpecifyLabelsInPulRequest(labelEvent, mapOfLabels)
+		if err != nil {
+			return err
+		}
+	}
+
+	message := s.GetWelcomeMessage(owner, repo, login, first, MessageData{
+		"IsPullRequest": true,
+		"ClaSigned":     claSigned,
+		"ClaUnknown":    !claKnown,
+	})
+	if message == "" {
+		return nil
+	}
+	body := gitee.PullRequestCommentPostParam{}
+	body.AccessToken = s.Config.GiteeToken
+	body.Body = message
+	_, _, err := s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, event.PullRequest.Number, body)
+	if err != nil {
+		glog.Errorf("unable to add comment in pull request: %v", err)
+		return err
+	}
+	return nil
+}
+
+// WelcomeInIssue welcomes the author of issue, and adds first-contribution label for first-time contributors
+func (s *Server) WelcomeInIssue(event *gitee.IssueEvent) error {
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	login := event.Issue.User.Login
+	first := s.IsFirstContribution(owner, repo, login)
+	glog.Infof("welcome in issue. owner: %s repo: %s login: %s first: %v", owner, repo, login, first)
+
+	if first {
+		mapOfLabels := map[string]string{LabelNameFirstContribution: LabelNameFirstContribution}
+		err := s.CreateLabelsIfNotExist(owner, repo, mapOfLabels, "")
+		if err != nil {
+			return err
+		}
+		labelEvent := &gitee.NoteEvent{}
+		labelEvent.Issue = event.Issue
+		labelEvent.Repository = event.Repository
+		labelEvent.Comment = &gitee.Note{}
+		err = s.UpdateSpecifyLabelsInIssue(labelEvent, mapOfLabels, map[string]string{})
+		if err != nil {
+			return err
+		}
+	}
+
+	message := s.GetWelcomeMessage(owner, repo, login, first, nil)
+	if message == "" {
+		return nil
+	}
+	body := gitee.IssueCommentPostParam{}
+	body.AccessToken = s.Config.GiteeToken
+	body.Body = message
+	_, _, err := s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, event.Issue.Number, body)
+	if err != nil {
+		glog.Errorf("unable to add comment in issue: %v", err)
+		return err
+	}
+	return nil
+}
@@ -15,6 +15,8 @@ var (
 
 type OwnersFile struct {
 	Maintainers []string `yaml:"maintainers"`
+	Reviewers   []string `yaml:"reviewers"`
+	Approvers   []string `yaml:"approvers"`
 }
 
 // CheckIsOwner checks the author is owner in repository
@@ -45,22 +47,50 @@ func (s *Server) GetOwners(event *gitee.NoteEvent) []string {
 	branch := event.PullRequest.Base.Ref
 	glog.Infof("get owners started. owner: %s repo: %s branch: %s", owner, repo, branch)
 
+	owners, err := s.GetOwnersFile(owner, repo, branch, DefaultOwnerFileName)
+	if err != nil {
+		return nil
+	}
+
+	// return owners
+	if len(owners.Maintainers) > 0 {
+		return owners.Maintainers
+	}
+
+	return nil
+}
+
+// GetApprovers gets approvers from owners file in repository
+// maintainers are used if there are no approvers in owners file
+func (s *Server) GetApprovers(owner, repo, branch string) []string {
+	owners, err := s.GetOwnersFile(owner, repo, branch, DefaultOwnerFileName)
+	if err != nil {
+		return nil
+	}
+	if len(owners.Approvers) > 0 {
+		return owners.Approvers
+	}
+	return owners.Maintainers
+}
+
+// GetOwnersFile gets owners file by path in repository
+func (s *Server) GetOwnersFile(owner, repo, branch, path string) (*OwnersFile, error) {
 	localVarOptionals := &gitee.GetV5ReposOwnerRepoContentsPathOpts{}
 	localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
 	localVarOptionals.Ref = optional.NewString(branch)
 	// get contents
 	contents, _, err := s.GiteeClient.RepositoriesApi.GetV5ReposOwnerRepoContentsPath(
-		s.Context, owner, repo, DefaultOwnerFileName, localVarOptionals)
+		s.Context, owner, repo, path, localVarOptionals)
 	if err != nil {
 		glog.Errorf("unable to get repository content by path: %v", err)
-		return nil
+		return nil, err
 	}
 
 	// base64 decode
 	decodeBytes, err := base64.StdEncoding.DecodeString(contents.Content)
 	if err != nil {
 		glog.Errorf("decode content with error: %v", err)
-		return nil
+		return nil, err
 	}
 	// unmarshal owners file
 	var owners OwnersFile
@@ -69,10 +99,5 @@ func (s *Server) GetOwners(event *gitee.NoteEvent) []string {
 		glog.Errorf("fail to unmarshal owners: %v", err)
 	}
 
-	// return owners
-	if len(owners.Maintainers) > 0 {
-		return owners.Maintainers
-	}
-
-	return nil
+	return &owners, nil
 }
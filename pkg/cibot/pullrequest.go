@@ -2,6 +2,7 @@ package cibot
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"gitee.com/openeuler/go-gitee/gitee"
@@ -22,22 +23,69 @@ func (s *Server) HandlePullRequestEvent(event *gitee.PullRequestEvent) {
 	case "open":
 		glog.Info("received a pull request open event")
 
-		// add comment
-		body := gitee.PullRequestCommentPostParam{}
-		body.AccessToken = s.Config.GiteeToken
-		body.Body = fmt.Sprintf(tipBotMessage, event.Sender.Login, s.Config.CommunityName, s.Config.CommunityName,
-			s.Config.BotName, s.Config.CommandLink)
-		owner := event.Repository.Namespace
-		repo := event.Repository.Name
-		number := event.PullRequest.Number
-		_, _, err := s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, body)
+		// the unsigned commits are used by both welcome and cla check
+		listOfUnsigned, listOfEmails, err := s.GetListOfUnsignedCommitsInPullRequest(
+			event.Repository.Namespace, event.Repository.Name, event.PullRequest)
+		claKnown := err == nil
+		if err != nil {
+			glog.Errorf("failed to get unsigned commits: %v", err)
+		}
+
+		// welcome the author
+		err = s.WelcomeInPullRequest(event, claKnown, len(listOfUnsigned) == 0)
+		if err != nil {
+			glog.Errorf("failed to welcome in pull request: %v", err)
+		}
+
+		if claKnown {
+			err = s.CheckCLAByPullRequestEvent(event, listOfUnsigned, listOfEmails)
+			if err != nil {
+				glog.Errorf("failed to check cla by pull request event: %v", err)
+			}
+		}
+
+		// pick reviewers
+		err = s.AssignReviewers(event)
+		if err != nil {
+			glog.Errorf("failed to assign reviewers: %v", err)
+		}
+
+		// apply release note labels
+		err = s.SyncReleaseNoteLabel(event.Repository, event.PullRequest)
 		if err != nil {
-			glog.Errorf("unable to add comment in pull request: %v", err)
+			glog.Errorf("failed to sync release note label: %v", err)
 		}
 
-		err = s.CheckCLAByPullRequestEvent(event)
+		// apply size label
+		err = s.SyncSizeLabel(event.Repository, event.PullRequest)
 		if err != nil {
-			glog.Errorf("failed to check cla by pull request event: %v", err)
+			glog.Errorf("failed to sync size label: %v", err)
+		}
+
+		// apply labels by the rules of changed files
+		err = s.SyncLabelRules(event.Repository, event.PullRequest)
+		if err != nil {
+			glog.Errorf("failed to sync label rules: %v", err)
+		}
+
+		// check the messages of commits
+		err = s.SyncCommitCheck(event.Repository, event.PullRequest, false)
+		if err != nil {
+			glog.Errorf("failed to check commits: %v", err)
+		}
+	case "close", "merge":
+		glog.Infof("received a pull request %s event", *event.Action)
+
+		// the review requests are finished
+		err := s.CloseReviewRequests(event.Repository.Namespace, event.Repository.Name, event.PullRequest.Number)
+		if err != nil {
+			glog.Errorf("failed to close review requests: %v", err)
+		}
+
+		// no need to check cla again
+		err = DeleteCLAPullRequests(event.Repository.Namespace, event.Repository.Name, event.PullRequest.Number)
+		if err != nil {
+			glog.Errorf("failed to delete cla pull requests: %v", err)
 		}
 	case "update":
 		glog.Info("received a pull request update event")
@@ -56,6 +104,42 @@ func (s *Server) HandlePullRequestEvent(event *gitee.PullRequestEvent) {
 		listofPrLabels := pr.Labels
 		glog.Infof("List of pr labels: %v", listofPrLabels)
 
+		// add or remove needs-rebase label
+		err = s.SyncRebaseLabel(event.Repository, &pr)
+		if err != nil {
+			glog.Errorf("unable to sync rebase label. err: %v", err)
+		}
+
+		// apply release note labels
+		err = s.SyncReleaseNoteLabel(event.Repository, &pr)
+		if err != nil {
+			glog.Errorf("unable to sync release note label. err: %v", err)
+		}
+
+		// apply size label
+		err = s.SyncSizeLabel(event.Repository, &pr)
+		if err != nil {
+			glog.Errorf("unable to sync size label. err: %v", err)
+		}
+
+		// apply labels by the rules of changed files
+		err = s.SyncLabelRules(event.Repository, &pr)
+		if err != nil {
+			glog.Errorf("unable to sync label rules. err: %v", err)
+		}
+
+		// check the messages of commits
+		err = s.SyncCommitCheck(event.Repository, &pr, false)
+		if err != nil {
+			glog.Errorf("unable to check commits. err: %v", err)
+		}
+
+		// check cla of the new commits
+		err = s.CheckCLAByPullRequestUpdate(event.Repository, &pr)
+		if err != nil {
+			glog.Errorf("unable to check cla. err: %v", err)
+		}
+
 		// check if it has lgtm label
 		hasLgtm := false
 		for _, l := range listofPrLabels {
@@ -81,29 +165,12 @@ func (s *Server) RemoveAssigneesInPullRequest(event *gitee.NoteEvent) error {
 		if event.PullRequest != nil {
 			assignees := event.PullRequest.Assignees
 			glog.Infof("remove assignees: %v", assignees)
-			if len(assignees) > 0 {
-				var strAssignees string
-				for _, assignee := range assignees {
-					strAssignees += assignee.Login + ","
-				}
-				strAssignees = strings.TrimRight(strAssignees, ",")
-				glog.Infof("remove assignees str: %s", strAssignees)
-
-				// get basic params
-				owner := event.Repository.Namespace
-				repo := event.Repository.Name
-				prNumber := event.PullRequest.Number
-				localVarOptionals := &gitee.DeleteV5ReposOwnerRepoPullsNumberAssigneesOpts{}
-				localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
-
-				// invoke api
-				_, _, err := s.GiteeClient.PullRequestsApi.DeleteV5ReposOwnerRepoPullsNumberAssignees(s.Context, owner, repo, prNumber, strAssignees, localVarOptionals)
-				if err != nil {
-					glog.Errorf("unable to remove assignees in pull request. err: %v", err)
-					return err
-				}
-				glog.Infof("remove assignees successfully: %s", strAssignees)
+			listOfAssignees := make([]string, 0, len(assignees))
+			for _, assignee := range assignees {
+				listOfAssignees = append(listOfAssignees, assignee.Login)
 			}
+			return s.RemoveSpecifyAssigneesInPullRequest(event.Repository.Namespace, event.Repository.Name,
+				event.PullRequest.Number, listOfAssignees)
 		}
 	}
 	return nil
@@ -115,30 +182,93 @@ func (s *Server) RemoveTestersInPullRequest(event *gitee.NoteEvent) error {
 		if event.PullRequest != nil {
 			testers := event.PullRequest.Testers
 			glog.Infof("remove testers: %v", testers)
-			if len(testers) > 0 {
-				var strTesters string
-				for _, tester := range testers {
-					strTesters += tester.Login + ","
-				}
-				strTesters = strings.TrimRight(strTesters, ",")
-				glog.Infof("remove testers str: %s", strTesters)
-
-				// get basic params
-				owner := event.Repository.Namespace
-				repo := event.Repository.Name
-				prNumber := event.PullRequest.Number
-				localVarOptionals := &gitee.DeleteV5ReposOwnerRepoPullsNumberTestersOpts{}
-				localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
-
-				// invoke api
-				_, _, err := s.GiteeClient.PullRequestsApi.DeleteV5ReposOwnerRepoPullsNumberTesters(s.Context, owner, repo, prNumber, strTesters, localVarOptionals)
-				if err != nil {
-					glog.Errorf("unable to remove testers in pull request. err: %v", err)
-					return err
-				}
-				glog.Infof("remove testers successfully: %s", strTesters)
+			listOfTesters := make([]string, 0, len(testers))
+			for _, tester := range testers {
+				listOfTesters = append(listOfTesters, tester.Login)
 			}
+			return s.RemoveSpecifyTestersInPullRequest(event.Repository.Namespace, event.Repository.Name,
+				event.PullRequest.Number, listOfTesters)
+		}
+	}
+	return nil
+}
+
+// AddSpecifyAssigneesInPullRequest add specify assignees in pull request
+func (s *Server) AddSpecifyAssigneesInPullRequest(owner, repo string, prNumber int32, assignees []string) error {
+	if len(assignees) > 0 {
+		strAssignees := strings.Join(assignees, ",")
+		glog.Infof("add assignees str: %s", strAssignees)
+
+		localVarOptionals := &gitee.PostV5ReposOwnerRepoPullsNumberAssigneesOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+
+		// invoke api
+		_, _, err := s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberAssignees(s.Context, owner, repo, prNumber, strAssignees, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to add assignees in pull request. err: %v", err)
+			return err
+		}
+		glog.Infof("add assignees successfully: %s", strAssignees)
+	}
+	return nil
+}
+
+// RemoveSpecifyAssigneesInPullRequest remove specify assignees in pull request
+func (s *Server) RemoveSpecifyAssigneesInPullRequest(owner, repo string, prNumber int32, assignees []string) error {
+	if len(assignees) > 0 {
+		strAssignees := strings.Join(assignees, ",")
+		glog.Infof("remove assignees str: %s", strAssignees)
+
+		localVarOptionals := &gitee.DeleteV5ReposOwnerRepoPullsNumberAssigneesOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+
+		// invoke api
+		_, _, err := s.GiteeClient.PullRequestsApi.DeleteV5ReposOwnerRepoPullsNumberAssignees(s.Context, owner, repo, prNumber, strAssignees, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to remove assignees in pull request. err: %v", err)
+			return err
+		}
+		glog.Infof("remove assignees successfully: %s", strAssignees)
+	}
+	return nil
+}
+
+// AddSpecifyTestersInPullRequest add specify testers in pull request
+func (s *Server) AddSpecifyTestersInPullRequest(owner, repo string, prNumber int32, testers []string) error {
+	if len(testers) > 0 {
+		strTesters := strings.Join(testers, ",")
+		glog.Infof("add testers str: %s", strTesters)
+
+		localVarOptionals := &gitee.PostV5ReposOwnerRepoPullsNumberTestersOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+
+		// invoke api
+		_, _, err := s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberTesters(s.Context, owner, repo, prNumber, strTesters, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to add testers in pull request. err: %v", err)
+			return err
+		}
+		glog.Infof("add testers successfully: %s", strTesters)
+	}
+	return nil
+}
+
+// RemoveSpecifyTestersInPullRequest remove specify testers in pull request
+func (s *Server) RemoveSpecifyTestersInPullRequest(owner, repo string, prNumber int32, testers []string) error {
+	if len(testers) > 0 {
+		strTesters := strings.Join(testers, ",")
+		glog.Infof("remove testers str: %s", strTesters)
+
+		localVarOptionals := &gitee.DeleteV5ReposOwnerRepoPullsNumberTestersOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+
+		// invoke api
+		_, _, err := s.GiteeClient.PullRequestsApi.DeleteV5ReposOwnerRepoPullsNumberTesters(s.Context, owner, repo, prNumber, strTesters, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to remove testers in pull request. err: %v", err)
+			return err
 		}
+		glog.Infof("remove testers successfully: %s", strTesters)
 	}
 	return nil
 }
@@ -151,7 +281,7 @@ func (s *Server) MergePullRequest(event *gitee.NoteEvent) error {
 	prNumber := event.PullRequest.Number
 	glog.Infof("merge pull request started. owner: %s repo: %s number: %d", owner, repo, prNumber)
 
-	// list labels in current pull request
+	// get current pull request
 	lvos := &gitee.GetV5ReposOwnerRepoPullsNumberOpts{}
 	lvos.AccessToken = optional.NewString(s.Config.GiteeToken)
 	pr, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumber(s.Context, owner, repo, prNumber, lvos)
@@ -159,47 +289,189 @@ func (s *Server) MergePullRequest(event *gitee.NoteEvent) error {
 		glog.Errorf("unable to get pull request. err: %v", err)
 		return err
 	}
-	listofPrLabels := pr.Labels
-	glog.Infof("List of pr labels: %v", listofPrLabels)
+	glog.Infof("List of pr labels: %v", pr.Labels)
+
+	// add or remove needs-rebase label
+	err = s.SyncRebaseLabel(event.Repository, &pr)
+	if err != nil {
+		glog.Errorf("unable to sync rebase label. err: %v", err)
+	}
+
+	// apply release note labels
+	err = s.SyncReleaseNoteLabel(event.Repository, &pr)
+	if err != nil {
+		glog.Errorf("unable to sync release note label. err: %v", err)
+	}
 
-	// check if it has both lgtm and approved label
-	hasApproved := false
-	hasLgtm := false
-	for _, l := range listofPrLabels {
-		if l.Name == LabelNameLgtm {
-			hasLgtm = true
-		} else if l.Name == LabelNameApproved {
-			hasApproved = true
+	// check if it is ready to merge
+	listOfMergeBlockers := s.GetListOfMergeBlockers(owner, repo, pr)
+	if len(listOfMergeBlockers) > 0 {
+		glog.Infof("pull request can not be merged: %v", listOfMergeBlockers)
+		message := s.RenderMessage(owner, repo, getPullRequestAuthor(pr), MessageMergeBlocked, MessageData{
+			"Blockers": listOfMergeBlockers,
+		})
+		// explain the blockers when the review is done, and keep the explained blockers up to date
+		if HasLabel(pr.Labels, LabelNameLgtm) && HasLabel(pr.Labels, LabelNameApproved) {
+			return s.UpdateMergeStatusComment(owner, repo, prNumber, message)
 		}
+		return s.UpdateExistingMergeStatusComment(owner, repo, prNumber, message)
 	}
-	glog.Infof("Pr labels have approved: %t lgtm: %t", hasApproved, hasLgtm)
 
-	// ready to merge
-	if hasApproved && hasLgtm {
-		// current pr can be merged
-		if event.PullRequest.Mergeable {
-			// remove assignees
-			err = s.RemoveAssigneesInPullRequest(event)
-			if err != nil {
-				glog.Errorf("unable to remove assignees. err: %v", err)
-				return err
+	// the merge queue merges one pull request at a time per branch, so it is not merged here
+	if MatchRepository(s.Config.MergeQueue.Repositories, owner, repo) {
+		glog.Infof("pull request is left to merge queue. owner: %s repo: %s number: %d", owner, repo, prNumber)
+		return s.UpdateExistingMergeStatusComment(owner, repo, prNumber,
+			s.RenderMessage(owner, repo, getPullRequestAuthor(pr), MessageMergeReady, nil))
+	}
+
+	return s.MergeReadyPullRequest(event.Repository, pr)
+}
+
+// MergeReadyPullRequest merges the pull request which is ready to merge
+func (s *Server) MergeReadyPullRequest(repository *gitee.Project, pr gitee.PullRequest) error {
+	// get basic params
+	owner := repository.Namespace
+	repo := repository.Name
+	prNumber := pr.Number
+	glog.Infof("merge ready pull request. owner: %s repo: %s number: %d", owner, repo, prNumber)
+
+	// the blockers explained before are resolved
+	err := s.UpdateExistingMergeStatusComment(owner, repo, prNumber,
+		s.RenderMessage(owner, repo, getPullRequestAuthor(pr), MessageMergeReady, nil))
+	if err != nil {
+		glog.Errorf("unable to update merge status comment. err: %v", err)
+	}
+
+	event := &gitee.NoteEvent{}
+	event.Repository = repository
+	event.PullRequest = &pr
+	// remove assignees
+	err = s.RemoveAssigneesInPullRequest(event)
+	if err != nil {
+		glog.Errorf("unable to remove assignees. err: %v", err)
+		return err
+	}
+	// remove testers
+	err = s.RemoveTestersInPullRequest(event)
+	if err != nil {
+		glog.Errorf("unable to remove testers. err: %v", err)
+		return err
+	}
+	// merge pr
+	body := gitee.PullRequestMergePutParam{}
+	body.AccessToken = s.Config.GiteeToken
+	body.MergeMethod = s.GetMergeMethod(owner, repo, pr)
+	if body.MergeMethod == MergeMethodSquash {
+		// squash commit message is generated from the pull request
+		body.Title = fmt.Sprintf("%s (!%d)", pr.Title, pr.Number)
+		body.Description = strings.TrimSpace(pr.Body)
+	}
+	glog.Infof("merge pull request with method: %s", body.MergeMethod)
+	_, err = s.GiteeClient.PullRequestsApi.PutV5ReposOwnerRepoPullsNumberMerge(s.Context, owner, repo, prNumber, body)
+	if err != nil {
+		glog.Errorf("unable to merge pull request. err: %v", err)
+		// explain the error returned by gitee
+		commentErr := s.UpdateMergeStatusComment(owner, repo, prNumber,
+			s.RenderMessage(owner, repo, getPullRequestAuthor(pr), MessageMergeFailed, MessageData{
+				"Error": GetGiteeErrorMessage(err),
+			}))
+		if commentErr != nil {
+			glog.Errorf("unable to update merge status comment. err: %v", commentErr)
+		}
+		return err
+	}
+	glog.Infof("merge pull request successfully. owner: %s repo: %s number: %d", owner, repo, prNumber)
+	return nil
+}
+
+// GetListOfMergeBlockers returns the reasons why the pull request can not be merged
+func (s *Server) GetListOfMergeBlockers(owner, repo string, pr gitee.PullRequest) []MergeBlocker {
+	listOfMergeBlockers := make([]MergeBlocker, 0)
+	// check required labels
+	for _, l := range s.GetRequiredLabels() {
+		if !HasLabel(pr.Labels, l) {
+			listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeMissingLabel, Label: l})
+		}
+	}
+	// check release note, even if the release-note-label-needed label is not blocking
+	releaseNoteMissing := s.IsReleaseNoteMissing(owner, repo, pr)
+	if releaseNoteMissing {
+		listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeReleaseNote})
+	}
+	// check blocking labels
+	for _, l := range s.GetListOfBlockingLabels(pr.Labels) {
+		switch l {
+		case LabelNameHold:
+			listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeHold})
+		case s.GetCLALabel(false):
+			listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeCLA})
+		case LabelNameReleaseNoteNeeded:
+			// the missing release note is reported above
+			if !releaseNoteMissing {
+				listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeReleaseNote})
 			}
-			// remove testers
-			err = s.RemoveTestersInPullRequest(event)
-			if err != nil {
-				glog.Errorf("unable to remove testers. err: %v", err)
-				return err
+		case LabelNameDCONo:
+			listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeDCO})
+		case LabelNameCommitMsgInvalid:
+			listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeCommitMessage})
+		case LabelNameRebase:
+			// conflicts are reported by mergeable
+			if pr.Mergeable {
+				listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeBlockingLabel, Label: l})
 			}
-			// merge pr
-			body := gitee.PullRequestMergePutParam{}
-			body.AccessToken = s.Config.GiteeToken
-			_, err = s.GiteeClient.PullRequestsApi.PutV5ReposOwnerRepoPullsNumberMerge(s.Context, owner, repo, prNumber, body)
+		default:
+			listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeBlockingLabel, Label: l})
+		}
+	}
+	// check the result of ci
+	listOfMergeBlockers = append(listOfMergeBlockers, s.GetListOfCIBlockers(owner, repo, pr)...)
+	// check conflicts
+	if !pr.Mergeable {
+		listOfMergeBlockers = append(listOfMergeBlockers, MergeBlocker{Type: mergeBlockerTypeConflicts})
+	}
+	return listOfMergeBlockers
+}
+
+// GetRequiredLabels returns the labels which are required to merge
+func (s *Server) GetRequiredLabels() []string {
+	if len(s.Config.RequiredLabels) > 0 {
+		return s.Config.RequiredLabels
+	}
+	// default required labels
+	return []string{LabelNameLgtm, LabelNameApproved}
+}
+
+// GetBlockingLabels returns the label patterns which block the merge
+func (s *Server) GetBlockingLabels() []string {
+	if len(s.Config.BlockingLabels) > 0 {
+		return s.Config.BlockingLabels
+	}
+	// default blocking labels
+	return []string{
+		"do-not-merge/*",
+		LabelNameRebase,
+		s.GetCLALabel(false),
+		LabelNameDCONo,
+		LabelNameCommitMsgInvalid,
+	}
+}
+
+// GetListOfBlockingLabels returns the labels in current item which block the merge
+func (s *Server) GetListOfBlockingLabels(listofItemLabels []gitee.Label) []string {
+	listOfBlockingLabels := make([]string, 0)
+	for _, l := range listofItemLabels {
+		for _, pattern := range s.GetBlockingLabels() {
+			// pattern supports wildcard. e.g do-not-merge/*
+			matched, err := path.Match(pattern, l.Name)
 			if err != nil {
-				glog.Errorf("unable to merge pull request. err: %v", err)
-				return err
+				glog.Errorf("invalid blocking label pattern: %s err: %v", pattern, err)
+				continue
+			}
+			if matched {
+				listOfBlockingLabels = append(listOfBlockingLabels, l.Name)
+				break
 			}
 		}
 	}
-
-	return nil
+	return listOfBlockingLabels
 }
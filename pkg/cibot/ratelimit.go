@@ -0,0 +1,174 @@
+package cibot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// default window of rate limit in seconds
+	defaultRateLimitWindow = 3600
+)
+
+// RateLimiter counts the commands sent by users and in repositories
+// the counts are kept in memory and database, so they survive the restarts
+type RateLimiter struct {
+	mutex  sync.Mutex
+	counts map[string]*database.CommandCounts
+	// the start of current window, which is zero before the first command after starting
+	windowStart time.Time
+}
+
+// NewRateLimiter returns the rate limiter
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		counts: map[string]*database.CommandCounts{},
+	}
+}
+
+// add adds the commands in current window and returns the count after adding,
+// and whether it is the first time to exceed the limit in current window
+// the login is empty for the count of repository
+func (rl *RateLimiter) add(owner, repo, login string, n, limit int, windowStart time.Time) (int, bool) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	// clean the counts of previous windows when the window rolls over,
+	// the counts saved before restarting are cleaned too
+	if !rl.windowStart.Equal(windowStart) {
+		rl.windowStart = windowStart
+		for k, c := range rl.counts {
+			if c.WindowStart.Before(windowStart) {
+				delete(rl.counts, k)
+			}
+		}
+		err := database.DBConnection.Unscoped().Where("window_start < ?", windowStart).
+			Delete(&database.CommandCounts{}).Error
+		if err != nil {
+			glog.Errorf("unable to delete command counts: %v", err)
+		}
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", owner, repo, login)
+	c, ok := rl.counts[key]
+	if !ok {
+		// load the count from database
+		var ccs []database.CommandCounts
+		err := database.DBConnection.Model(&database.CommandCounts{}).
+			Where("owner = ? and repo = ? and login = ? and window_start = ?", owner, repo, login, windowStart).
+			Find(&ccs).Error
+		if err != nil {
+			glog.Errorf("unable to get command counts: %v", err)
+		}
+		if len(ccs) > 0 {
+			c = &ccs[0]
+		} else {
+			c = &database.CommandCounts{
+				Owner:       owner,
+				Repo:        repo,
+				Login:       login,
+				WindowStart: windowStart,
+			}
+		}
+		rl.counts[key] = c
+	}
+
+	c.Count += n
+	firstExceeded := false
+	if c.Count > limit && !c.Warned {
+		c.Warned = true
+		firstExceeded = true
+	}
+
+	// save the count in database
+	var err error
+	if c.ID == 0 {
+		err = database.DBConnection.Create(c).Error
+	} else {
+		err = database.DBConnection.Model(c).Updates(map[string]interface{}{"count": c.Count, "warned": c.Warned}).Error
+	}
+	if err != nil {
+		glog.Errorf("unable to save command counts: %v", err)
+	}
+	return c.Count, firstExceeded
+}
+
+// isBlocked checks the user is in blocklist
+func (s *Server) isBlocked(login string) bool {
+	for _, b := range s.Config.RateLimit.Blocklist {
+		if b == login {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRateLimit checks the commands in comment can be handled
+// the commands of blocked users and the commands exceeding the limits are ignored
+func (s *Server) CheckRateLimit(event *gitee.NoteEvent) bool {
+	if event.Comment.User == nil || event.Comment.User.Login == s.Config.BotName {
+		return true
+	}
+	n := len(s.GetKnownCommandLines(event.Comment.Body))
+	if n == 0 {
+		return true
+	}
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	commentAuthor := event.Comment.User.Login
+	if s.isBlocked(commentAuthor) {
+		glog.Infof("ignore the commands of blocked user. owner: %s repo: %s commentAuthor: %s", owner, repo, commentAuthor)
+		return false
+	}
+
+	rl := s.RateLimiter
+	cfg := s.Config.RateLimit
+	if rl == nil || (cfg.UserLimit <= 0 && cfg.RepoLimit <= 0) {
+		return true
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = defaultRateLimitWindow
+	}
+	windowStart := time.Now().Truncate(time.Duration(window) * time.Second)
+	windowEnd := windowStart.Add(time.Duration(window) * time.Second).Format("2006-01-02 15:04:05 MST")
+
+	message := ""
+	exceeded := false
+	if cfg.UserLimit > 0 {
+		count, firstExceeded := rl.add(owner, repo, commentAuthor, n, cfg.UserLimit, windowStart)
+		if count > cfg.UserLimit {
+			exceeded = true
+			if firstExceeded || !cfg.QuietMode {
+				message = s.RenderMessage(owner, repo, commentAuthor, MessageRateLimitUser, MessageData{"Until": windowEnd})
+			}
+		}
+	}
+	// the ignored commands of user are not counted, so one user can not use up the limit of repository
+	if cfg.RepoLimit > 0 && !exceeded {
+		count, firstExceeded := rl.add(owner, repo, "", n, cfg.RepoLimit, windowStart)
+		if count > cfg.RepoLimit {
+			exceeded = true
+			if firstExceeded || !cfg.QuietMode {
+				message = s.RenderMessage(owner, repo, commentAuthor, MessageRateLimitRepo, MessageData{"Until": windowEnd})
+			}
+		}
+	}
+	if !exceeded {
+		return true
+	}
+
+	glog.Infof("ignore the commands exceeding rate limit. owner: %s repo: %s commentAuthor: %s", owner, repo, commentAuthor)
+	if message != "" {
+		err := s.AddCommentInNoteEvent(event, message)
+		if err != nil {
+			glog.Errorf("failed to add rate limit comment: %v", err)
+		}
+	}
+	return false
+}
@@ -0,0 +1,379 @@
+package cibot
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	LabelPrefixKind = "kind/"
+
+	// the prefix of ref in tag push event
+	refPrefixTags = "refs/tags/"
+	// the sha of ref when it is deleted
+	deletedSha = "0000000000000000000000000000000000000000"
+	// the header of admin token
+	adminTokenHeader = "X-Admin-Token"
+
+	releaseTitle          = "## Changes in %s\n"
+	releaseSinceTitle     = "## Changes since %s\n"
+	releaseOtherKind      = "other"
+	releaseNoChangesNote  = "no pull requests are merged."
+	releaseNoteItemFormat = "- %s (#%d, @%s)"
+)
+
+var (
+	// the reference of pull request in the message of merge or squash commit, e.g. "Merge pull request !12 from"
+	regPullRequestReference = regexp.MustCompile(`!(\d+)\b`)
+)
+
+// CompareItem defines the result of comparing two commits
+type CompareItem struct {
+	Commits []CommitItem `json:"commits"`
+}
+
+// TagItem defines the tag listed in repository
+// go-gitee fails to decode the commit of tag, so the used fields are defined here
+type TagItem struct {
+	Name   string    `json:"name"`
+	Commit TagCommit `json:"commit"`
+}
+
+// TagCommit defines the commit of tag
+type TagCommit struct {
+	Sha  string    `json:"sha"`
+	Date time.Time `json:"date"`
+}
+
+// ReleaseItem defines the release of repository
+// go-gitee fails to decode the prerelease and assets of release, so the used fields are defined here
+type ReleaseItem struct {
+	ID      int32  `json:"id"`
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+}
+
+// ReleaseNotesHandler previews the release notes of tag without creating the release
+type ReleaseNotesHandler struct {
+	Server
+}
+
+// HandleTagPushEvent generates the release notes and creates or updates the release of pushed tag
+func (s *Server) HandleTagPushEvent(event *gitee.PushEvent) {
+	if event == nil || event.Ref == nil || event.Repository == nil {
+		return
+	}
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	if !MatchRepository(s.Config.Release.Repositories, owner, repo) {
+		return
+	}
+	if !strings.HasPrefix(*event.Ref, refPrefixTags) {
+		return
+	}
+	// ignore the deleted tag
+	if (event.Deleted != nil && *event.Deleted) || (event.After != nil && *event.After == deletedSha) {
+		return
+	}
+	tag := strings.TrimPrefix(*event.Ref, refPrefixTags)
+	glog.Infof("tag push event is triggered. owner: %s repo: %s tag: %s", owner, repo, tag)
+
+	tags, err := s.ListTags(owner, repo)
+	if err != nil {
+		return
+	}
+	current, previous := findTags(tags, tag)
+	if current == nil {
+		glog.Errorf("unable to find the tag. owner: %s repo: %s tag: %s", owner, repo, tag)
+		return
+	}
+	notes, err := s.GenerateReleaseNotes(owner, repo, current, previous)
+	if err != nil {
+		return
+	}
+	err = s.SaveRelease(owner, repo, current, notes)
+	if err != nil {
+		glog.Errorf("unable to save release. owner: %s repo: %s tag: %s err: %v", owner, repo, tag, err)
+		return
+	}
+	glog.Infof("save release successfully. owner: %s repo: %s tag: %s", owner, repo, tag)
+}
+
+// ListTags lists all tags in repository
+func (s *Server) ListTags(owner, repo string) ([]TagItem, error) {
+	tags := make([]TagItem, 0)
+	for page := int32(1); ; page++ {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(int(page)))
+		query.Set("per_page", strconv.Itoa(int(giteeMaxPerPage)))
+		var list []TagItem
+		err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/tags", owner, repo), query, nil, &list)
+		if err != nil {
+			glog.Errorf("unable to list tags. owner: %s repo: %s err: %v", owner, repo, err)
+			return nil, err
+		}
+		tags = append(tags, list...)
+		if int32(len(list)) < giteeMaxPerPage {
+			break
+		}
+	}
+	return tags, nil
+}
+
+// ListCommitsBetween lists the commits reachable from head and not from base,
+// all the commits reachable from head are listed if base is empty
+func (s *Server) ListCommitsBetween(owner, repo, base, head string) ([]CommitItem, error) {
+	if base != "" {
+		var result CompareItem
+		err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/compare/%s...%s", owner, repo, base, head), nil, nil, &result)
+		if err != nil {
+			glog.Errorf("unable to compare commits. owner: %s repo: %s base: %s head: %s err: %v", owner, repo, base, head, err)
+			return nil, err
+		}
+		return result.Commits, nil
+	}
+
+	commits := make([]CommitItem, 0)
+	for page := int32(1); ; page++ {
+		query := url.Values{}
+		query.Set("sha", head)
+		query.Set("page", strconv.Itoa(int(page)))
+		query.Set("per_page", strconv.Itoa(int(giteeMaxPerPage)))
+		var list []CommitItem
+		err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/commits", owner, repo), query, nil, &list)
+		if err != nil {
+			glog.Errorf("unable to list commits. owner: %s repo: %s head: %s err: %v", owner, repo, head, err)
+			return nil, err
+		}
+		commits = append(commits, list...)
+		if int32(len(list)) < giteeMaxPerPage {
+			break
+		}
+	}
+	return commits, nil
+}
+
+// findTags finds the tag and the latest tag before it
+func findTags(tags []TagItem, tag string) (*TagItem, *TagItem) {
+	var current *TagItem
+	for i := range tags {
+		if tags[i].Name == tag {
+			current = &tags[i]
+			break
+		}
+	}
+	if current == nil {
+		return nil, nil
+	}
+
+	var previous *TagItem
+	for i := range tags {
+		if tags[i].Name == tag || !tags[i].Commit.Date.Before(current.Commit.Date) {
+			continue
+		}
+		if previous == nil || tags[i].Commit.Date.After(previous.Commit.Date) {
+			previous = &tags[i]
+		}
+	}
+	return current, previous
+}
+
+// GenerateReleaseNotes generates the release notes by the pull requests which are in current tag and not in previous tag
+// the pull requests are grouped by kind labels, and the release notes are preferred to the titles
+func (s *Server) GenerateReleaseNotes(owner, repo string, current, previous *TagItem) (string, error) {
+	// only the pull requests merged after previous tag are listed
+	var since time.Time
+	previousSha := ""
+	if previous != nil {
+		since = previous.Commit.Date
+		previousSha = previous.Commit.Sha
+	}
+	prs, err := s.ListMergedPullRequestsSince(owner, repo, since)
+	if err != nil {
+		return "", err
+	}
+
+	// the pull request is in current tag if its head commit is reachable from the tag,
+	// or it is referred by the merge or squash commit which is reachable from the tag
+	commits, err := s.ListCommitsBetween(owner, repo, previousSha, current.Commit.Sha)
+	if err != nil {
+		return "", err
+	}
+	mapOfShas := map[string]bool{}
+	mapOfNumbers := map[int32]bool{}
+	for _, c := range commits {
+		mapOfShas[c.Sha] = true
+		for _, m := range regPullRequestReference.FindAllStringSubmatch(c.Commit.Message, -1) {
+			number, err := strconv.Atoi(m[1])
+			if err == nil {
+				mapOfNumbers[int32(number)] = true
+			}
+		}
+	}
+
+	var rns []database.ReleaseNotes
+	err = database.DBConnection.Model(&database.ReleaseNotes{}).
+		Where("owner = ? and repo = ?", owner, repo).Find(&rns).Error
+	if err != nil {
+		glog.Errorf("unable to get release notes: %v", err)
+		return "", err
+	}
+	mapOfNotes := map[int32]string{}
+	for _, rn := range rns {
+		mapOfNotes[rn.Number] = rn.Note
+	}
+
+	// sort by merged time, so the items keep the order of merging
+	sort.SliceStable(prs, func(i, j int) bool {
+		return prs[i].MergedAt < prs[j].MergedAt
+	})
+
+	mapOfKinds := map[string][]string{}
+	for _, pr := range prs {
+		inTag := mapOfNumbers[pr.Number] || (pr.Head != nil && mapOfShas[pr.Head.Sha])
+		if !inTag {
+			continue
+		}
+		if HasLabel(pr.Labels, LabelNameReleaseNoteNone) {
+			continue
+		}
+
+		note, ok := mapOfNotes[pr.Number]
+		if !ok || isReleaseNoteNone(note) {
+			note = pr.Title
+		}
+		prAuthor := ""
+		if pr.User != nil {
+			prAuthor = pr.User.Login
+		}
+		kind := releaseOtherKind
+		for _, l := range pr.Labels {
+			if strings.HasPrefix(l.Name, LabelPrefixKind) {
+				kind = strings.TrimPrefix(l.Name, LabelPrefixKind)
+				break
+			}
+		}
+		// the multi-line note is joined as one item
+		note = strings.Join(strings.Fields(note), " ")
+		mapOfKinds[kind] = append(mapOfKinds[kind], fmt.Sprintf(releaseNoteItemFormat, note, pr.Number, prAuthor))
+	}
+
+	return buildReleaseNotes(current, previous, mapOfKinds), nil
+}
+
+// buildReleaseNotes builds the release notes of the items grouped by kind
+func buildReleaseNotes(current, previous *TagItem, mapOfKinds map[string][]string) string {
+	listOfLines := make([]string, 0)
+	if previous != nil {
+		listOfLines = append(listOfLines, fmt.Sprintf(releaseSinceTitle, previous.Name))
+	} else {
+		listOfLines = append(listOfLines, fmt.Sprintf(releaseTitle, current.Name))
+	}
+	if len(mapOfKinds) == 0 {
+		listOfLines = append(listOfLines, releaseNoChangesNote)
+		return strings.Join(listOfLines, "\n")
+	}
+
+	// the kind of other is the last one
+	listOfKinds := make([]string, 0, len(mapOfKinds))
+	for kind := range mapOfKinds {
+		if kind != releaseOtherKind {
+			listOfKinds = append(listOfKinds, kind)
+		}
+	}
+	sort.Strings(listOfKinds)
+	if _, ok := mapOfKinds[releaseOtherKind]; ok {
+		listOfKinds = append(listOfKinds, releaseOtherKind)
+	}
+
+	for _, kind := range listOfKinds {
+		listOfLines = append(listOfLines, fmt.Sprintf("### %s\n", strings.Title(kind)))
+		listOfLines = append(listOfLines, mapOfKinds[kind]...)
+		listOfLines = append(listOfLines, "")
+	}
+	return strings.TrimSpace(strings.Join(listOfLines, "\n"))
+}
+
+// SaveRelease creates the release of tag, or updates the notes if the release exists
+func (s *Server) SaveRelease(owner, repo string, tag *TagItem, notes string) error {
+	var release *ReleaseItem
+	err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/releases/tags/%s", owner, repo, url.PathEscape(tag.Name)), nil, nil, &release)
+	if err != nil {
+		if e, ok := err.(GiteeAPIError); !ok || e.StatusCode != http.StatusNotFound {
+			return err
+		}
+		release = nil
+	}
+
+	if release != nil && release.ID > 0 {
+		glog.Infof("update release. owner: %s repo: %s tag: %s id: %d", owner, repo, tag.Name, release.ID)
+		body := map[string]interface{}{
+			"tag_name": tag.Name,
+			"name":     release.Name,
+			"body":     notes,
+		}
+		return s.CallGiteeAPI("PATCH", fmt.Sprintf("/repos/%s/%s/releases/%d", owner, repo, release.ID), nil, body, nil)
+	}
+
+	glog.Infof("create release. owner: %s repo: %s tag: %s", owner, repo, tag.Name)
+	body := map[string]interface{}{
+		"tag_name":         tag.Name,
+		"name":             tag.Name,
+		"body":             notes,
+		"target_commitish": tag.Commit.Sha,
+		"prerelease":       false,
+	}
+	return s.CallGiteeAPI("POST", fmt.Sprintf("/repos/%s/%s/releases", owner, repo), nil, body, nil)
+}
+
+// ServeHTTP outputs the release notes of tag for preview
+// e.g. /release-notes?owner=openeuler&repo=ci-bot&tag=v1.0.0
+func (handler *ReleaseNotesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get(adminTokenHeader)
+	if handler.Config.AdminToken == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(handler.Config.AdminToken)) != 1 {
+		http.Error(w, "invalid admin token", http.StatusUnauthorized)
+		return
+	}
+
+	owner := r.URL.Query().Get("owner")
+	repo := r.URL.Query().Get("repo")
+	tag := r.URL.Query().Get("tag")
+	if owner == "" || repo == "" || tag == "" {
+		http.Error(w, "owner, repo and tag are required", http.StatusBadRequest)
+		return
+	}
+	glog.Infof("preview release notes. owner: %s repo: %s tag: %s", owner, repo, tag)
+
+	tags, err := handler.ListTags(owner, repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	current, previous := findTags(tags, tag)
+	if current == nil {
+		http.Error(w, "tag is not found", http.StatusNotFound)
+		return
+	}
+	notes, err := handler.GenerateReleaseNotes(owner, repo, current, previous)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(notes))
+}
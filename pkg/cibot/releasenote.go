@@ -0,0 +1,220 @@
+package cibot
+
+import (
+	"strings"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	LabelNameReleaseNote       = "release-note"
+	LabelNameReleaseNoteNone   = "release-note-none"
+	LabelNameReleaseNoteNeeded = "do-not-merge/release-note-label-needed"
+
+	// the release note which means no release note is needed
+	releaseNoteNone = "NONE"
+)
+
+// GetReleaseNoteFromBody gets the release note in ```release-note``` block of pull request description
+func GetReleaseNoteFromBody(body string) (string, bool) {
+	m := RegReleaseNoteBlock.FindStringSubmatch(body)
+	if m == nil {
+		return "", false
+	}
+	return strings.TrimSpace(m[1]), true
+}
+
+// isReleaseNoteNone checks the release note means no release note is needed
+func isReleaseNoteNone(note string) bool {
+	return note == "" || strings.ToUpper(note) == releaseNoteNone
+}
+
+// SyncReleaseNoteLabel applies the release note labels by the description of pull request
+// the labels set by commands are kept if there is no release note block in description
+// the labels of pull request are updated after syncing
+func (s *Server) SyncReleaseNoteLabel(repository *gitee.Project, pr *gitee.PullRequest) error {
+	owner := repository.Namespace
+	repo := repository.Name
+	if !MatchRepository(s.Config.ReleaseNote.Repositories, owner, repo) {
+		return nil
+	}
+
+	label := LabelNameReleaseNoteNeeded
+	note, found := GetReleaseNoteFromBody(pr.Body)
+	if found {
+		label = LabelNameReleaseNote
+		if isReleaseNoteNone(note) {
+			label = LabelNameReleaseNoteNone
+		} else {
+			prAuthor := ""
+			if pr.User != nil {
+				prAuthor = pr.User.Login
+			}
+			err := SaveReleaseNote(owner, repo, pr.Number, prAuthor, note)
+			if err != nil {
+				return err
+			}
+		}
+	} else if HasLabel(pr.Labels, LabelNameReleaseNote) {
+		label = LabelNameReleaseNote
+	} else if HasLabel(pr.Labels, LabelNameReleaseNoteNone) {
+		label = LabelNameReleaseNoteNone
+	}
+	glog.Infof("sync release note label. owner: %s repo: %s number: %d label: %s", owner, repo, pr.Number, label)
+
+	event := &gitee.NoteEvent{}
+	event.PullRequest = pr
+	event.Repository = repository
+	event.Comment = &gitee.Note{}
+	return s.setReleaseNoteLabel(event, pr, label)
+}
+
+// setReleaseNoteLabel adds the release note label and removes the others
+func (s *Server) setReleaseNoteLabel(event *gitee.NoteEvent, pr *gitee.PullRequest, label string) error {
+	mapOfAddLabels := map[string]string{}
+	mapOfRemoveLabels := map[string]string{}
+	changed := false
+	for _, l := range []string{LabelNameReleaseNote, LabelNameReleaseNoteNone, LabelNameReleaseNoteNeeded} {
+		hasLabel := HasLabel(pr.Labels, l)
+		if l == label && !hasLabel {
+			mapOfAddLabels[l] = l
+			changed = true
+		}
+		if l != label && hasLabel {
+			mapOfRemoveLabels[l] = l
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	err := s.CreateLabelsIfNotExist(event.Repository.Namespace, event.Repository.Name, mapOfAddLabels, "")
+	if err != nil {
+		return err
+	}
+	err = s.UpdateSpecifyLabelsInPulRequest(event, mapOfAddLabels, mapOfRemoveLabels)
+	if err != nil {
+		return err
+	}
+
+	listofLabels := make([]gitee.Label, 0)
+	for _, l := range pr.Labels {
+		if _, ok := mapOfRemoveLabels[l.Name]; !ok {
+			listofLabels = append(listofLabels, l)
+		}
+	}
+	for l := range mapOfAddLabels {
+		listofLabels = append(listofLabels, gitee.Label{Name: l})
+	}
+	pr.Labels = listofLabels
+	return nil
+}
+
+// IsReleaseNoteMissing checks the pull request in opted-in repository has neither release note nor no release note label
+// the release-note-label-needed label is not required, since it may be failed to add
+func (s *Server) IsReleaseNoteMissing(owner, repo string, pr gitee.PullRequest) bool {
+	if !MatchRepository(s.Config.ReleaseNote.Repositories, owner, repo) {
+		return false
+	}
+	return !HasLabel(pr.Labels, LabelNameReleaseNote) && !HasLabel(pr.Labels, LabelNameReleaseNoteNone)
+}
+
+// SetReleaseNote sets the release note of pull request by /release-note and /release-note-none
+func (s *Server) SetReleaseNote(event *gitee.NoteEvent) error {
+	// handle PullRequest
+	if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			// get basic params
+			comment := event.Comment.Body
+			owner := event.Repository.Namespace
+			repo := event.Repository.Name
+			prAuthor := event.PullRequest.User.Login
+			prNumber := event.PullRequest.Number
+			commentAuthor := event.Comment.User.Login
+			glog.Infof("set release note started. comment: %s prAuthor: %s commentAuthor: %s owner: %s repo: %s number: %d",
+				comment, prAuthor, commentAuthor, owner, repo, prNumber)
+
+			// get the release note from comment
+			note := releaseNoteNone
+			if !RegReleaseNoteNone.MatchString(comment) {
+				m := RegReleaseNote.FindStringSubmatch(comment)
+				if m == nil {
+					return nil
+				}
+				note = strings.TrimSpace(m[1])
+				if note == "" {
+					return s.AddCommentInNoteEvent(event, s.RenderMessage(owner, repo, commentAuthor, MessageReleaseNoteEmpty, nil))
+				}
+			}
+
+			// check if current author can set release note
+			hasPermission, err := s.CheckPullRequestPermission(event, commentAuthor)
+			if err != nil {
+				return err
+			}
+			if !hasPermission {
+				return s.AddCommentInNoteEvent(event, s.RenderMessage(owner, repo, commentAuthor, MessageReleaseNoteNoPermission, nil))
+			}
+
+			label := LabelNameReleaseNoteNone
+			message := s.RenderMessage(owner, repo, commentAuthor, MessageReleaseNoteNone, nil)
+			if !isReleaseNoteNone(note) {
+				label = LabelNameReleaseNote
+				message = s.RenderMessage(owner, repo, commentAuthor, MessageReleaseNoteSet, nil)
+				err = SaveReleaseNote(owner, repo, prNumber, commentAuthor, note)
+				if err != nil {
+					return err
+				}
+			}
+			err = s.setReleaseNoteLabel(event, event.PullRequest, label)
+			if err != nil {
+				return err
+			}
+			err = s.AddCommentInNoteEvent(event, message)
+			if err != nil {
+				return err
+			}
+			// try to merge pr
+			return s.MergePullRequest(event)
+		}
+	}
+	return nil
+}
+
+// SaveReleaseNote adds or updates the release note of pull request
+func SaveReleaseNote(owner, repo string, number int32, author, note string) error {
+	var rns []database.ReleaseNotes
+	err := database.DBConnection.Model(&database.ReleaseNotes{}).
+		Where("owner = ? and repo = ? and number = ?", owner, repo, number).Find(&rns).Error
+	if err != nil {
+		glog.Errorf("unable to get release notes: %v", err)
+		return err
+	}
+
+	if len(rns) > 0 {
+		if rns[0].Note == note {
+			return nil
+		}
+		err = database.DBConnection.Model(&rns[0]).Updates(map[string]interface{}{"author": author, "note": note}).Error
+		if err != nil {
+			glog.Errorf("unable to update release notes: %v", err)
+		}
+		return err
+	}
+
+	addrn := database.ReleaseNotes{
+		Owner:  owner,
+		Repo:   repo,
+		Number: number,
+		Author: author,
+		Note:   note,
+	}
+	err = database.DBConnection.Create(&addrn).Error
+	if err != nil {
+		glog.Errorf("unable to add release notes: %v", err)
+	}
+	return err
+}
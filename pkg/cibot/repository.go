@@ -0,0 +1,244 @@
+package cibot
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+const (
+	// the max number of items per page in gitee api
+	giteeMaxPerPage int32 = 100
+)
+
+// RepositoryName defines the owner and name of a repository
+type RepositoryName struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+}
+
+// IssueItem defines the issue listed in repository
+// go-gitee fails to decode the repository of issue, so the used fields are defined here
+type IssueItem struct {
+	Number    string           `json:"number"`
+	State     string           `json:"state"`
+	Title     string           `json:"title"`
+	User      *gitee.UserBasic `json:"user"`
+	Labels    []gitee.Label    `json:"labels"`
+	Assignee  *gitee.UserBasic `json:"assignee"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// CommitItem defines the commit of pull request returned by gitee api
+// go-gitee decodes the commit as string, so the gitee api is called directly
+type CommitItem struct {
+	Sha     string         `json:"sha"`
+	Commit  CommitDetail   `json:"commit"`
+	Parents []CommitParent `json:"parents"`
+}
+
+// CommitDetail defines the detail of commit
+type CommitDetail struct {
+	Author    CommitUser `json:"author"`
+	Committer CommitUser `json:"committer"`
+	Message   string     `json:"message"`
+}
+
+// CommitUser defines the author or committer of commit
+type CommitUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// CommitParent defines the parent of commit
+type CommitParent struct {
+	Sha string `json:"sha"`
+}
+
+// MatchRepository checks the repository is in the list of "owner/repo" or "owner"
+func MatchRepository(list []string, owner, repo string) bool {
+	for _, r := range list {
+		if r == owner || r == owner+"/"+repo {
+			return true
+		}
+	}
+	return false
+}
+
+// FindRepositoryConfig returns the index of config entry whose repositories match the repository, -1 if not found
+// the entry of "owner/repo" is preferred to the entry of "owner"
+func FindRepositoryConfig(count int, repositories func(i int) []string, owner, repo string) int {
+	for _, key := range []string{owner + "/" + repo, owner} {
+		for i := 0; i < count; i++ {
+			for _, r := range repositories(i) {
+				if r == key {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// ListRepositories expands the list of "owner/repo" or "owner" to repositories
+func (s *Server) ListRepositories(list []string) []RepositoryName {
+	repositories := make([]RepositoryName, 0)
+	for _, r := range list {
+		substrings := strings.SplitN(r, "/", 2)
+		if len(substrings) == 2 {
+			// owner/repo
+			repositories = append(repositories, RepositoryName{Owner: substrings[0], Repo: substrings[1]})
+			continue
+		}
+
+		// owner: list all repositories in organization
+		org := substrings[0]
+		for page := int32(1); ; page++ {
+			localVarOptionals := &gitee.GetV5OrgsOrgReposOpts{}
+			localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+			localVarOptionals.Page = optional.NewInt32(page)
+			localVarOptionals.PerPage = optional.NewInt32(giteeMaxPerPage)
+			projects, _, err := s.GiteeClient.RepositoriesApi.GetV5OrgsOrgRepos(s.Context, org, localVarOptionals)
+			if err != nil {
+				glog.Errorf("unable to list repositories in organization: %s err: %v", org, err)
+				break
+			}
+			for _, p := range projects {
+				repositories = append(repositories, RepositoryName{Owner: org, Repo: p.Path})
+			}
+			if int32(len(projects)) < giteeMaxPerPage {
+				break
+			}
+		}
+	}
+	return repositories
+}
+
+// ListPullRequests lists all pull requests with state in repository
+func (s *Server) ListPullRequests(owner, repo, state string) ([]gitee.PullRequest, error) {
+	prs := make([]gitee.PullRequest, 0)
+	for page := int32(1); ; page++ {
+		localVarOptionals := &gitee.GetV5ReposOwnerRepoPullsOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+		localVarOptionals.State = optional.NewString(state)
+		localVarOptionals.Page = optional.NewInt32(page)
+		localVarOptionals.PerPage = optional.NewInt32(giteeMaxPerPage)
+		list, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPulls(s.Context, owner, repo, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to list pull requests. owner: %s repo: %s err: %v", owner, repo, err)
+			return nil, err
+		}
+		prs = append(prs, list...)
+		if int32(len(list)) < giteeMaxPerPage {
+			break
+		}
+	}
+	return prs, nil
+}
+
+// ListMergedPullRequestsSince lists the pull requests merged after since in repository
+// the pull requests are listed from the latest updated, and the listing stops at the pull requests updated before since,
+// because a pull request is always updated when it is merged
+func (s *Server) ListMergedPullRequestsSince(owner, repo string, since time.Time) ([]gitee.PullRequest, error) {
+	prs := make([]gitee.PullRequest, 0)
+	for page := int32(1); ; page++ {
+		localVarOptionals := &gitee.GetV5ReposOwnerRepoPullsOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+		localVarOptionals.State = optional.NewString("merged")
+		localVarOptionals.Sort = optional.NewString("updated")
+		localVarOptionals.Direction = optional.NewString("desc")
+		localVarOptionals.Page = optional.NewInt32(page)
+		localVarOptionals.PerPage = optional.NewInt32(giteeMaxPerPage)
+		list, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPulls(s.Context, owner, repo, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to list merged pull requests. owner: %s repo: %s err: %v", owner, repo, err)
+			return nil, err
+		}
+		done := false
+		for _, pr := range list {
+			updatedAt, err := time.Parse(time.RFC3339, pr.UpdatedAt)
+			if err == nil && updatedAt.Before(since) {
+				done = true
+				break
+			}
+			prs = append(prs, pr)
+		}
+		if done || int32(len(list)) < giteeMaxPerPage {
+			break
+		}
+	}
+	return prs, nil
+}
+
+// ListPullRequestComments lists all comments in pull request
+func (s *Server) ListPullRequestComments(owner, repo string, number int32) ([]gitee.PullRequestComments, error) {
+	comments := make([]gitee.PullRequestComments, 0)
+	for page := int32(1); ; page++ {
+		localVarOptionals := &gitee.GetV5ReposOwnerRepoPullsNumberCommentsOpts{}
+		localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+		localVarOptionals.Page = optional.NewInt32(page)
+		localVarOptionals.PerPage = optional.NewInt32(giteeMaxPerPage)
+		list, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, localVarOptionals)
+		if err != nil {
+			glog.Errorf("unable to get pull request comments. owner: %s repo: %s number: %d err: %v", owner, repo, number, err)
+			return nil, err
+		}
+		comments = append(comments, list...)
+		if int32(len(list)) < giteeMaxPerPage {
+			break
+		}
+	}
+	return comments, nil
+}
+
+// ListPullRequestFiles lists the changed files in pull request
+func (s *Server) ListPullRequestFiles(owner, repo string, number int32) ([]gitee.PullRequestFiles, error) {
+	localVarOptionals := &gitee.GetV5ReposOwnerRepoPullsNumberFilesOpts{}
+	localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+	files, _, err := s.GiteeClient.PullRequestsApi.GetV5ReposOwnerRepoPullsNumberFiles(s.Context, owner, repo, number, localVarOptionals)
+	if err != nil {
+		glog.Errorf("unable to get pull request files. owner: %s repo: %s number: %d err: %v", owner, repo, number, err)
+		return nil, err
+	}
+	return files, nil
+}
+
+// ListPullRequestCommits lists the commits in pull request
+func (s *Server) ListPullRequestCommits(owner, repo string, number int32) ([]CommitItem, error) {
+	var commits []CommitItem
+	err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/pulls/%d/commits", owner, repo, number), nil, nil, &commits)
+	if err != nil {
+		glog.Errorf("unable to get pull request commits. owner: %s repo: %s number: %d err: %v", owner, repo, number, err)
+		return nil, err
+	}
+	return commits, nil
+}
+
+// ListIssues lists all issues with state in repository
+func (s *Server) ListIssues(owner, repo, state string) ([]IssueItem, error) {
+	issues := make([]IssueItem, 0)
+	for page := int32(1); ; page++ {
+		query := url.Values{}
+		query.Set("state", state)
+		query.Set("page", strconv.Itoa(int(page)))
+		query.Set("per_page", strconv.Itoa(int(giteeMaxPerPage)))
+		var list []IssueItem
+		err := s.CallGiteeAPI("GET", fmt.Sprintf("/repos/%s/%s/issues", owner, repo), query, nil, &list)
+		if err != nil {
+			glog.Errorf("unable to list issues. owner: %s repo: %s err: %v", owner, repo, err)
+			return nil, err
+		}
+		issues = append(issues, list...)
+		if int32(len(list)) < giteeMaxPerPage {
+			break
+		}
+	}
+	return issues, nil
+}
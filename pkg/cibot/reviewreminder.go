@@ -0,0 +1,287 @@
+package cibot
+
+import (
+	"time"
+
+	"gitee.com/openeuler/ci-bot/pkg/cibot/database"
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	// default duration of review reminder in seconds
+	defaultReviewReminderDuration = 3600
+	// default hours to wait before reminding the reviewers
+	defaultReviewSLA = 48
+
+	// the stages of review reminder
+	reviewReminderStageReviewers = 1
+	reviewReminderStageApprovers = 2
+)
+
+// ReviewReminderHandler reminds the reviewers who do not review in time
+type ReviewReminderHandler struct {
+	Server
+}
+
+// Serve checks the review requests periodically
+func (handler *ReviewReminderHandler) Serve() {
+	if len(handler.Config.ReviewReminder.Repositories) == 0 {
+		return
+	}
+
+	for {
+		duration := handler.Config.ReviewReminder.Duration
+		if duration <= 0 {
+			duration = defaultReviewReminderDuration
+		}
+		glog.Info("begin to check review reminders")
+		handler.sync()
+		glog.Info("end to check review reminders")
+		time.Sleep(time.Duration(duration) * time.Second)
+	}
+}
+
+// getSLA returns the hours to wait before reminding the reviewers and the approvers
+func (handler *ReviewReminderHandler) getSLA() (int, int) {
+	sla := handler.Config.ReviewReminder.SLA
+	if sla <= 0 {
+		sla = defaultReviewSLA
+	}
+	escalationSLA := handler.Config.ReviewReminder.EscalationSLA
+	if escalationSLA <= sla {
+		escalationSLA = sla * 2
+	}
+	return sla, escalationSLA
+}
+
+// sync checks all open pull requests in repositories
+func (handler *ReviewReminderHandler) sync() {
+	for _, r := range handler.ListRepositories(handler.Config.ReviewReminder.Repositories) {
+		prs, err := handler.ListPullRequests(r.Owner, r.Repo, "open")
+		if err != nil {
+			continue
+		}
+		mapOfNumbers := map[int32]bool{}
+		for i := range prs {
+			mapOfNumbers[prs[i].Number] = true
+			err = handler.checkPullRequest(r.Owner, r.Repo, &prs[i])
+			if err != nil {
+				glog.Errorf("unable to check review reminder. owner: %s repo: %s number: %d err: %v",
+					r.Owner, r.Repo, prs[i].Number, err)
+			}
+		}
+		handler.cleanReminders(r.Owner, r.Repo, mapOfNumbers)
+	}
+}
+
+// checkPullRequest reminds the reviewers who do not comment after requested
+func (handler *ReviewReminderHandler) checkPullRequest(owner, repo string, pr *gitee.PullRequest) error {
+	prAuthor := ""
+	if pr.User != nil {
+		prAuthor = pr.User.Login
+	}
+	// the requested reviewers are assignees and testers
+	listOfReviewers := make([]string, 0)
+	mapOfReviewers := map[string]bool{}
+	for _, users := range [][]gitee.UserBasic{pr.Assignees, pr.Testers} {
+		for _, u := range users {
+			if u.Login != prAuthor && !mapOfReviewers[u.Login] {
+				mapOfReviewers[u.Login] = true
+				listOfReviewers = append(listOfReviewers, u.Login)
+			}
+		}
+	}
+	if len(listOfReviewers) == 0 {
+		return nil
+	}
+
+	prCreatedAt, err := time.Parse(time.RFC3339, pr.CreatedAt)
+	if err != nil {
+		glog.Errorf("invalid created time of pull request: %s err: %v", pr.CreatedAt, err)
+		return err
+	}
+
+	// the time of review requests recorded by bot
+	var rrs []database.ReviewRequests
+	err = database.DBConnection.Model(&database.ReviewRequests{}).
+		Where("owner = ? and repo = ? and number = ?", owner, repo, pr.Number).Find(&rrs).Error
+	if err != nil {
+		glog.Errorf("unable to get review requests: %v", err)
+		return err
+	}
+	mapOfRequestedAt := map[string]time.Time{}
+	for _, rr := range rrs {
+		mapOfRequestedAt[rr.Reviewer] = rr.CreatedAt
+	}
+
+	// the reminders already sent
+	var rms []database.ReviewReminders
+	err = database.DBConnection.Model(&database.ReviewReminders{}).
+		Where("owner = ? and repo = ? and number = ?", owner, repo, pr.Number).Find(&rms).Error
+	if err != nil {
+		glog.Errorf("unable to get review reminders: %v", err)
+		return err
+	}
+
+	comments, err := handler.ListPullRequestComments(owner, repo, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	sla, escalationSLA := handler.getSLA()
+	mapOfStages := map[int][]string{}
+	mapOfRequestedAtByStage := map[int]map[string]time.Time{}
+	for _, reviewer := range listOfReviewers {
+		requestedAt, ok := mapOfRequestedAt[reviewer]
+		if !ok {
+			requestedAt = prCreatedAt
+		}
+		requestedAt = requestedAt.Truncate(time.Second)
+
+		// check if the reviewer comments or votes after requested
+		if hasCommentAfter(comments, reviewer, requestedAt) {
+			continue
+		}
+
+		stage := 0
+		waiting := time.Since(requestedAt)
+		if waiting >= time.Duration(escalationSLA)*time.Hour {
+			stage = reviewReminderStageApprovers
+		} else if waiting >= time.Duration(sla)*time.Hour {
+			stage = reviewReminderStageReviewers
+		}
+		if stage == 0 || hasReminder(rms, reviewer, stage, requestedAt) {
+			continue
+		}
+		mapOfStages[stage] = append(mapOfStages[stage], reviewer)
+		if mapOfRequestedAtByStage[stage] == nil {
+			mapOfRequestedAtByStage[stage] = map[string]time.Time{}
+		}
+		mapOfRequestedAtByStage[stage][reviewer] = requestedAt
+	}
+
+	// remind the reviewers
+	if listOfReminded := mapOfStages[reviewReminderStageReviewers]; len(listOfReminded) > 0 {
+		glog.Infof("remind reviewers. owner: %s repo: %s number: %d reviewers: %v", owner, repo, pr.Number, listOfReminded)
+		err = handler.addReminderComment(owner, repo, pr.Number,
+			handler.RenderMessage(owner, repo, "", MessageReviewReminder, MessageData{
+				"Reviewers": GetMentions(listOfReminded),
+				"Hours":     sla,
+			}))
+		if err != nil {
+			return err
+		}
+		err = addReminders(owner, repo, pr.Number, reviewReminderStageReviewers, mapOfRequestedAtByStage[reviewReminderStageReviewers])
+		if err != nil {
+			return err
+		}
+	}
+
+	// mention the approvers
+	if listOfReminded := mapOfStages[reviewReminderStageApprovers]; len(listOfReminded) > 0 {
+		branch := ""
+		if pr.Base != nil {
+			branch = pr.Base.Ref
+		}
+		approvers := handler.GetApprovers(owner, repo, branch)
+		if len(approvers) == 0 {
+			glog.Infof("no approvers are found in OWNERS file. owner: %s repo: %s", owner, repo)
+			return nil
+		}
+		glog.Infof("remind approvers. owner: %s repo: %s number: %d reviewers: %v approvers: %v",
+			owner, repo, pr.Number, listOfReminded, approvers)
+		err = handler.addReminderComment(owner, repo, pr.Number,
+			handler.RenderMessage(owner, repo, "", MessageReviewEscalation, MessageData{
+				"Approvers": GetMentions(approvers),
+				"Reviewers": GetMentions(listOfReminded),
+				"Hours":     escalationSLA,
+			}))
+		if err != nil {
+			return err
+		}
+		err = addReminders(owner, repo, pr.Number, reviewReminderStageApprovers, mapOfRequestedAtByStage[reviewReminderStageApprovers])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addReminderComment adds the reminder comment in pull request
+func (handler *ReviewReminderHandler) addReminderComment(owner, repo string, number int32, message string) error {
+	body := gitee.PullRequestCommentPostParam{}
+	body.AccessToken = handler.Config.GiteeToken
+	body.Body = message
+	_, _, err := handler.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(handler.Context, owner, repo, number, body)
+	if err != nil {
+		glog.Errorf("unable to add comment in pull request: %v", err)
+	}
+	return err
+}
+
+// cleanReminders deletes the reminders of pull requests which are not open any more
+func (handler *ReviewReminderHandler) cleanReminders(owner, repo string, mapOfNumbers map[int32]bool) {
+	var rms []database.ReviewReminders
+	err := database.DBConnection.Model(&database.ReviewReminders{}).
+		Where("owner = ? and repo = ?", owner, repo).Find(&rms).Error
+	if err != nil {
+		glog.Errorf("unable to get review reminders: %v", err)
+		return
+	}
+	for i := range rms {
+		if !mapOfNumbers[rms[i].Number] {
+			err = database.DBConnection.Delete(&rms[i]).Error
+			if err != nil {
+				glog.Errorf("unable to delete review reminders: %v", err)
+			}
+		}
+	}
+}
+
+// hasCommentAfter checks the login comments after the time
+func hasCommentAfter(comments []gitee.PullRequestComments, login string, t time.Time) bool {
+	for _, c := range comments {
+		if c.User == nil || c.User.Login != login {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, c.CreatedAt)
+		if err != nil {
+			continue
+		}
+		if !createdAt.Before(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasReminder checks the reminder of stage is already sent in the same review request
+func hasReminder(rms []database.ReviewReminders, reviewer string, stage int, requestedAt time.Time) bool {
+	for _, rm := range rms {
+		if rm.Reviewer == reviewer && rm.Stage == stage && rm.RequestedAt.Equal(requestedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// addReminders records the reminders sent to reviewers
+func addReminders(owner, repo string, number int32, stage int, mapOfRequestedAt map[string]time.Time) error {
+	for reviewer, requestedAt := range mapOfRequestedAt {
+		addrm := database.ReviewReminders{
+			Owner:       owner,
+			Repo:        repo,
+			Number:      number,
+			Reviewer:    reviewer,
+			Stage:       stage,
+			RequestedAt: requestedAt,
+		}
+		err := database.DBConnection.Create(&addrm).Error
+		if err != nil {
+			glog.Errorf("unable to add review reminders: %v", err)
+			return err
+		}
+	}
+	return nil
+}
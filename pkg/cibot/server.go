@@ -2,6 +2,7 @@ package cibot
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -14,6 +15,8 @@ type Server struct {
 	Config      config.Config
 	Context     context.Context
 	GiteeClient *gitee.APIClient
+	RateLimiter *RateLimiter
+	Templates   *MessageTemplates
 }
 
 // ServeHTTP validates an incoming webhook and invoke its handler.
@@ -59,5 +62,13 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		go s.HandlePullRequestEvent(event.(*gitee.PullRequestEvent))
 	case *gitee.TagPushEvent:
 		glog.Info("received a tag push event")
+		// the tag push event in go-gitee has no detail, so the payload is parsed as push event
+		var pushEvent gitee.PushEvent
+		err = json.Unmarshal(payload, &pushEvent)
+		if err != nil {
+			glog.Errorf("failed to parse tag push event: %v", err)
+			return
+		}
+		go s.HandleTagPushEvent(&pushEvent)
 	}
 }
@@ -0,0 +1,129 @@
+package cibot
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/golang/glog"
+)
+
+const (
+	LabelNameSizeXS  = "size/XS"
+	LabelNameSizeS   = "size/S"
+	LabelNameSizeM   = "size/M"
+	LabelNameSizeL   = "size/L"
+	LabelNameSizeXL  = "size/XL"
+	LabelNameSizeXXL = "size/XXL"
+
+	// default minimal changed lines of each size
+	defaultSizeS   = 10
+	defaultSizeM   = 30
+	defaultSizeL   = 100
+	defaultSizeXL  = 500
+	defaultSizeXXL = 1000
+)
+
+// listOfSizeLabels defines all size labels from small to large
+var listOfSizeLabels = []string{LabelNameSizeXS, LabelNameSizeS, LabelNameSizeM, LabelNameSizeL, LabelNameSizeXL, LabelNameSizeXXL}
+
+// GetSizeLabel returns the size label of changed lines
+func (s *Server) GetSizeLabel(lines int) string {
+	thresholds := []int{s.Config.Size.S, s.Config.Size.M, s.Config.Size.L, s.Config.Size.XL, s.Config.Size.XXL}
+	defaults := []int{defaultSizeS, defaultSizeM, defaultSizeL, defaultSizeXL, defaultSizeXXL}
+	label := LabelNameSizeXS
+	for i := range thresholds {
+		threshold := thresholds[i]
+		if threshold <= 0 {
+			threshold = defaults[i]
+		}
+		if lines >= threshold {
+			label = listOfSizeLabels[i+1]
+		}
+	}
+	return label
+}
+
+// isExcludedPath checks the changes in file are not counted
+// the path ends with "/" matches the directory, and the others match the file path or name by pattern
+func (s *Server) isExcludedPath(filename string) bool {
+	for _, p := range s.Config.Size.ExcludedPaths {
+		if strings.HasSuffix(p, "/") {
+			if strings.HasPrefix(filename, p) || strings.Contains(filename, "/"+p) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(p, filename); matched {
+			return true
+		}
+		if matched, _ := path.Match(p, path.Base(filename)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChangedLines returns the added and deleted lines of pull request except the excluded paths
+func (s *Server) GetChangedLines(owner, repo string, prNumber int32) (int, error) {
+	files, err := s.ListPullRequestFiles(owner, repo, prNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	lines := 0
+	for _, f := range files {
+		if s.isExcludedPath(f.Filename) {
+			continue
+		}
+		additions, _ := strconv.Atoi(f.Additions)
+		deletions, _ := strconv.Atoi(f.Deletions)
+		lines += additions + deletions
+	}
+	return lines, nil
+}
+
+// SyncSizeLabel applies the size label by the changed lines of pull request
+// the old size label is replaced in the same request
+func (s *Server) SyncSizeLabel(repository *gitee.Project, pr *gitee.PullRequest) error {
+	owner := repository.Namespace
+	repo := repository.Name
+	if !MatchRepository(s.Config.Size.Repositories, owner, repo) {
+		return nil
+	}
+
+	lines, err := s.GetChangedLines(owner, repo, pr.Number)
+	if err != nil {
+		return err
+	}
+	label := s.GetSizeLabel(lines)
+	glog.Infof("sync size label. owner: %s repo: %s number: %d lines: %d label: %s", owner, repo, pr.Number, lines, label)
+
+	mapOfAddLabels := map[string]string{}
+	mapOfRemoveLabels := map[string]string{}
+	for _, l := range listOfSizeLabels {
+		hasLabel := HasLabel(pr.Labels, l)
+		if l == label && !hasLabel {
+			mapOfAddLabels[l] = l
+		}
+		if l != label && hasLabel {
+			mapOfRemoveLabels[l] = l
+		}
+	}
+	if len(mapOfAddLabels) == 0 && len(mapOfRemoveLabels) == 0 {
+		return nil
+	}
+	if len(mapOfAddLabels) > 0 {
+		err = s.CreateLabelsIfNotExist(owner, repo, mapOfAddLabels, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	event := &gitee.NoteEvent{}
+	event.PullRequest = pr
+	event.Repository = repository
+	event.Comment = &gitee.Note{}
+	return s.UpdateSpecifyLabelsInPulRequest(event, mapOfAddLabels, mapOfRemoveLabels)
+}
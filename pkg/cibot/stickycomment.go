@@ -0,0 +1,101 @@
+package cibot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gitee.com/openeuler/go-gitee/gitee"
+	"github.com/antihax/optional"
+	"github.com/golang/glog"
+)
+
+const (
+	// StickyCommentHiddenValue is the hidden marker of the kind of sticky comment
+	StickyCommentHiddenValue = "<input type=hidden name=%s />"
+
+	// the kinds of sticky comments
+	StickyCommentKindMergeStatus = "merge-status"
+	StickyCommentKindCLA         = "cla"
+	StickyCommentKindCommitCheck = "commit-check"
+)
+
+// getStickyCommentMarker returns the hidden marker of the kind of sticky comment
+func getStickyCommentMarker(kind string) string {
+	return fmt.Sprintf(StickyCommentHiddenValue, kind)
+}
+
+// UpdateStickyCommentInPullRequest updates the last comment of bot with the marker of kind, or adds it if not existing
+// so there is only one comment of each kind in pull request
+func (s *Server) UpdateStickyCommentInPullRequest(owner, repo string, number int32, kind, message string) error {
+	return s.updateStickyCommentInPullRequest(owner, repo, number, kind, message, false)
+}
+
+// UpdateExistingStickyCommentInPullRequest updates the last comment of bot with the marker of kind
+// nothing is added if not existing, e.g. the resolved state is only shown after the problems are reported
+func (s *Server) UpdateExistingStickyCommentInPullRequest(owner, repo string, number int32, kind, message string) error {
+	return s.updateStickyCommentInPullRequest(owner, repo, number, kind, message, true)
+}
+
+// GetStickyCommentInPullRequest returns the last comment of bot with the marker of kind, nil if not existing
+func (s *Server) GetStickyCommentInPullRequest(owner, repo string, number int32, kind string) (*gitee.PullRequestComments, error) {
+	marker := getStickyCommentMarker(kind)
+	comments, err := s.ListPullRequestComments(owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	var lastComment *gitee.PullRequestComments
+	for i := range comments {
+		if comments[i].User != nil && comments[i].User.Login == s.Config.BotName &&
+			strings.Contains(comments[i].Body, marker) {
+			lastComment = &comments[i]
+		}
+	}
+	return lastComment, nil
+}
+
+// updateStickyCommentInPullRequest updates the sticky comment of kind, and adds it if not existing and not onlyExisting
+func (s *Server) updateStickyCommentInPullRequest(owner, repo string, number int32, kind, message string, onlyExisting bool) error {
+	message = message + getStickyCommentMarker(kind)
+
+	// find the last sticky comment of bot
+	lastComment, err := s.GetStickyCommentInPullRequest(owner, repo, number, kind)
+	if err != nil {
+		return err
+	}
+
+	if lastComment == nil {
+		if onlyExisting {
+			return nil
+		}
+		// add comment
+		body := gitee.PullRequestCommentPostParam{}
+		body.AccessToken = s.Config.GiteeToken
+		body.Body = message
+		_, _, err = s.GiteeClient.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(s.Context, owner, repo, number, body)
+		if err != nil {
+			glog.Errorf("unable to add comment in pull request: %v", err)
+			return err
+		}
+		return nil
+	}
+
+	// no need to update the same comment
+	if lastComment.Body == message {
+		glog.Infof("sticky comment %s is not changed: %s", kind, lastComment.Id)
+		return nil
+	}
+	id, err := strconv.Atoi(lastComment.Id)
+	if err != nil {
+		glog.Errorf("invalid comment id: %s err: %v", lastComment.Id, err)
+		return err
+	}
+	localVarOptionals := &gitee.PatchV5ReposOwnerRepoPullsCommentsIdOpts{}
+	localVarOptionals.AccessToken = optional.NewString(s.Config.GiteeToken)
+	_, _, err = s.GiteeClient.PullRequestsApi.PatchV5ReposOwnerRepoPullsCommentsId(s.Context, owner, repo, int32(id), message, localVarOptionals)
+	if err != nil {
+		glog.Errorf("unable to update comment in pull request: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,543 @@
+package cibot
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// the names of message templates
+	MessageOnboarding                  = "onboarding"
+	MessageWelcome                     = "welcome"
+	MessageClaFound                    = "cla-found"
+	MessageClaNotFound                 = "cla-not-found"
+	MessageLgtmSelfOwn                 = "lgtm-self-own"
+	MessageLgtmAdded                   = "lgtm-added"
+	MessageLgtmRemoved                 = "lgtm-removed"
+	MessageLgtmAddNoPermission         = "lgtm-add-no-permission"
+	MessageLgtmRemoveNoPermission      = "lgtm-remove-no-permission"
+	MessageLgtmRemovePullRequestChange = "lgtm-remove-pull-request-change"
+	MessageApprovedAdded               = "approved-added"
+	MessageApprovedRemoved             = "approved-removed"
+	MessageApprovedAddNoPermission     = "approved-add-no-permission"
+	MessageApprovedRemoveNoPermission  = "approved-remove-no-permission"
+	MessageHoldAdded                   = "hold-added"
+	MessageHoldRemoved                 = "hold-removed"
+	MessageHoldAddNoPermission         = "hold-add-no-permission"
+	MessageHoldRemoveNoPermission      = "hold-remove-no-permission"
+	MessageMergeBlocked                = "merge-blocked"
+	MessageMergeReady                  = "merge-ready"
+	MessageMergeFailed                 = "merge-failed"
+	MessageMergeMethodSet              = "merge-method-set"
+	MessageMergeMethodInvalid          = "merge-method-invalid"
+	MessageMergeMethodNoPermission     = "merge-method-no-permission"
+	MessageNeedsRebase                 = "needs-rebase"
+	MessageLifecycleStale              = "lifecycle-stale"
+	MessageLifecycleRotten             = "lifecycle-rotten"
+	MessageLifecycleClose              = "lifecycle-close"
+	MessageLifecycleAdded              = "lifecycle-added"
+	MessageLifecycleRemoved            = "lifecycle-removed"
+	MessageReleaseNoteSet              = "release-note-set"
+	MessageReleaseNoteNone             = "release-note-none"
+	MessageReleaseNoteEmpty            = "release-note-empty"
+	MessageReleaseNoteNoPermission     = "release-note-no-permission"
+	MessageLabelNotAllowed             = "label-not-allowed"
+	MessageLabelNoAllowlist            = "label-no-allowlist"
+	MessageUnknownLabels               = "unknown-labels"
+	MessageNoValidLabels               = "no-valid-labels"
+	MessageRateLimitUser               = "rate-limit-user"
+	MessageRateLimitRepo               = "rate-limit-repo"
+	MessageCommitCheckPassed           = "commit-check-passed"
+	MessageCommitCheckFailed           = "commit-check-failed"
+	MessageAssign                      = "assign"
+	MessageUnassign                    = "unassign"
+	MessageCC                          = "cc"
+	MessageUncc                        = "uncc"
+	MessageBlunderbuss                 = "blunderbuss"
+	MessageReviewReminder              = "review-reminder"
+	MessageReviewEscalation            = "review-escalation"
+	MessageCloseIssue                  = "close-issue"
+	MessageReopenIssue                 = "reopen-issue"
+
+	// the locale of built-in templates
+	defaultLocale = "en"
+	// the extension of template files
+	templateFileExt = ".tmpl"
+	// the separator between the messages of locales in bilingual output
+	localeSeparator = "\n\n---\n\n"
+)
+
+// defaultMessageTemplates are the built-in templates in english
+// they are overridden by the template files, e.g. en/tip-bot.tmpl
+var defaultMessageTemplates = map[string]string{
+	MessageOnboarding: `Hey ***@{{.Login}}***, Welcome to {{.CommunityName}} Community, and thanks for your first contribution. :tada:
+All of the projects in {{.CommunityName}} Community are maintained by ***@{{.BotName}}***.
+That means the developpers can comment below every pull request or issue to trigger Bot Commands.
+Please follow instructions at <{{.CommandLink}}> to find the details.
+{{- if .IsPullRequest}}
+{{- if .ClaUnknown}}
+- you'll need to sign {{.CommunityName}} CLA at <{{.ClaLink}}> if you haven't, and comment **/check-cla** to verify.
+{{- else if .ClaSigned}}
+- you've already signed {{.CommunityName}} CLA. :wave:
+{{- else}}
+- you'll need to sign {{.CommunityName}} CLA at <{{.ClaLink}}> before your pull request can be merged, and then comment **/check-cla** to verify.
+{{- end}}
+- the reviewers are assigned automatically. you can also comment **/cc @username** to request a review from someone.
+{{- end}}
+{{- if .ContributingLink}}
+- please read the contribution docs at <{{.ContributingLink}}>.
+{{- end}}`,
+	MessageWelcome:  `Thanks for your contribution, ***@{{.Login}}***. the Bot Commands are listed at <{{.CommandLink}}>. :wave: `,
+	MessageClaFound: `Thanks for your pull request. you've already signed {{.CommunityName}} CLA successfully. :wave: `,
+	MessageClaNotFound: `Thanks for your pull request.
+**Before we can look at your pull request, you'll need to sign a Contributor License Agreement (CLA).**
+**Please follow instructions at <{{.ClaLink}}> to sign the CLA.**
+{{- if .UnsignedCommits}}
+The emails of these commits have not signed the CLA:
+{{- range .UnsignedCommits}}
+- {{.Sha}}: {{.Emails}}
+{{- end}}
+{{end}}
+It may take a couple minutes for the CLA signature to be fully registered;
+after that, please reply here with a new comment **/check-cla** and we'll verify.
+- If you've already signed a CLA, it's possible we don't have your Gitee username or you're using a different email address.
+  Check your existing CLA data and verify that your email at <https://gitee.com/profile/emails>.
+- If you have done the above and are still having issues with the CLA being reported as unsigned,
+  send a message to the backup e-mail support address at: {{.ContactEmail}}
+`,
+	MessageLgtmSelfOwn: `***lgtm*** can not be added in your self-own pull request. :astonished: `,
+	MessageLgtmAdded:   `***lgtm*** is added in this pull request by: ***@{{.Login}}***. :wave: `,
+	MessageLgtmRemoved: `***lgtm*** is removed in this pull request by: ***@{{.Login}}***. :flushed: `,
+	MessageLgtmAddNoPermission: `***@{{.Login}}*** has no permission to add ***lgtm*** in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageLgtmRemoveNoPermission: `***@{{.Login}}*** has no permission to remove ***lgtm*** in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageLgtmRemovePullRequestChange: `new changes are detected. ***lgtm*** is removed in this pull request by: ***@{{.BotName}}***. :flushed: `,
+	MessageApprovedAdded:               `***approved*** is added in this pull request by: ***@{{.Login}}***. :wave: `,
+	MessageApprovedRemoved:             `***approved*** is removed in this pull request by: ***@{{.Login}}***. :flushed: `,
+	MessageApprovedAddNoPermission: `***@{{.Login}}*** has no permission to add ***approved*** in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageApprovedRemoveNoPermission: `***@{{.Login}}*** has no permission to remove ***approved*** in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageHoldAdded:   `***{{.Label}}*** is added in this pull request by: ***@{{.Login}}***. this pull request will not be merged until the hold is cancelled by ***/hold cancel***. :raised_hand: `,
+	MessageHoldRemoved: `***{{.Label}}*** is removed in this pull request by: ***@{{.Login}}***. :wave: `,
+	MessageHoldAddNoPermission: `***@{{.Login}}*** has no permission to add ***{{.Label}}*** in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageHoldRemoveNoPermission: `***@{{.Login}}*** has no permission to remove ***{{.Label}}*** in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageMergeBlocked: `this pull request can not be merged yet. :astonished:
+the following problems are blocking the merge:
+{{- range .Blockers}}
+{{- if eq .Type "missing-label"}}
+- label ***{{.Label}}*** is missing.
+{{- else if eq .Type "hold"}}
+- this pull request is on hold. comment ***/hold cancel*** to remove the hold.
+{{- else if eq .Type "cla"}}
+- the CLA is not signed. please follow instructions at <{{$.ClaLink}}> to sign the CLA.
+{{- else if eq .Type "release-note"}}
+- the release note is missing. please add a ` + "```release-note```" + ` block in the description, or comment ***/release-note <note>*** or ***/release-note-none***.
+{{- else if eq .Type "dco"}}
+- some commits are not signed off by the authors. please add ***Signed-off-by*** in the commit messages.
+{{- else if eq .Type "commit-message"}}
+- some commit messages are invalid. please amend them by the rules.
+{{- else if eq .Type "ci-pending"}}
+- the ci has not passed yet. label ***{{.Label}}*** is required.
+{{- else if eq .Type "ci-failed"}}
+- the ci failed. please fix it and run the ci again.
+{{- else if eq .Type "conflicts"}}
+- this pull request has conflicts with the target branch. please rebase it.
+{{- else}}
+- label ***{{.Label}}*** is blocking the merge.
+{{- end}}
+{{- end}}`,
+	MessageMergeReady: `all the merge blockers are resolved. this pull request is ready to merge. :wave: `,
+	MessageMergeFailed: `this pull request can not be merged. :astonished:
+gitee returns the error: {{.Error}}`,
+	MessageMergeMethodSet: `merge method of this pull request is set to ***{{.Method}}*** by: ***@{{.Login}}***. :wave: `,
+	MessageMergeMethodInvalid: `***{{.Method}}*** is not a valid merge method. :astonished:
+please choose one of: ***merge***, ***squash*** and ***rebase***.`,
+	MessageMergeMethodNoPermission: `***@{{.Login}}*** has no permission to set merge method in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageNeedsRebase: `***@{{.Login}}***, this pull request has conflicts with the target branch and can not be merged. :astonished:
+please rebase it, the label ***{{.Label}}*** will be removed automatically after rebasing.`,
+	MessageLifecycleStale: `this {{if .IsPullRequest}}pull request{{else}}issue{{end}} has had no activity for {{.Days}} days, so it is marked as ***{{.Label}}***. :zzz:
+it will be marked as ***{{.RottenLabel}}*** after {{.RottenDays}} more days of inactivity, and closed after that.
+please leave a comment or comment ***/remove-lifecycle stale*** to keep it active, or comment ***/lifecycle frozen*** if it should never be marked.`,
+	MessageLifecycleRotten: `this {{if .IsPullRequest}}pull request{{else}}issue{{end}} has had no activity for {{.Days}} days since it was marked as stale, so it is marked as ***{{.Label}}***. :zzz:
+it will be closed after {{.CloseDays}} more days of inactivity.
+please leave a comment or comment ***/remove-lifecycle rotten*** to keep it active.`,
+	MessageLifecycleClose: `this {{if .IsPullRequest}}pull request{{else}}issue{{end}} is closed, because it has had no activity for {{.Days}} days since it was marked as rotten. :wave:
+please comment ***/reopen*** if it is still needed.`,
+	MessageLifecycleAdded:   `***{{.Label}}*** is added in this {{if .IsPullRequest}}pull request{{else}}issue{{end}} by: ***@{{.Login}}***.`,
+	MessageLifecycleRemoved: `***{{.Label}}*** is removed in this {{if .IsPullRequest}}pull request{{else}}issue{{end}} by: ***@{{.Login}}***.`,
+	MessageReleaseNoteSet:   `the release note of this pull request is set by: ***@{{.Login}}***. :memo: `,
+	MessageReleaseNoteNone:  `this pull request is marked as no release note needed by: ***@{{.Login}}***. :memo: `,
+	MessageReleaseNoteEmpty: `the release note can not be empty. :astonished:
+please comment ***/release-note <note>***, or ***/release-note-none*** if no release note is needed.`,
+	MessageReleaseNoteNoPermission: `***@{{.Login}}*** has no permission to set the release note in this pull request. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageLabelNotAllowed: `{{.Labels}} can not be added or removed by ***/label*** or ***/remove-label***, because they are not in the allowlist. :astonished:
+the allowed labels are: {{.AllowedLabels}}`,
+	MessageLabelNoAllowlist: `{{.Labels}} can not be added or removed by ***/label*** or ***/remove-label***, because no label is allowed. :astonished:`,
+	MessageUnknownLabels: `{{.Labels}} can not be added, because they are not existing in this repository. :astonished:
+the valid labels are:
+{{- range .ValidLabels}}
+- ***{{.}}***
+{{- end}}`,
+	MessageNoValidLabels: `{{.Labels}} can not be added, because they are not existing in this repository. :astonished:
+please contact to the collaborators in this repository.`,
+	MessageRateLimitUser:     `***@{{.Login}}*** has sent too many commands in this repository, so the commands are ignored until {{.Until}}. :no_entry:`,
+	MessageRateLimitRepo:     `too many commands are sent in this repository, so the commands are ignored until {{.Until}}. :no_entry:`,
+	MessageCommitCheckPassed: `all the commits in this pull request pass the checks. :wave: `,
+	MessageCommitCheckFailed: `some commits in this pull request do not pass the checks. :astonished:
+{{- range .Failures}}
+{{- if eq .Type "dco"}}
+- {{.Sha}}: ***Signed-off-by: {{.Name}} <{{.Email}}>*** is missing.
+{{- else if eq .Type "subject-length"}}
+- {{.Sha}}: the subject is longer than {{.MaxSubjectLength}} characters.
+{{- else}}
+- {{.Sha}}: the subject does not match ***` + "`{{.SubjectPattern}}`" + `***.
+{{- end}}
+{{- end}}
+
+please amend the commit messages and push again, then comment ***/check-dco*** to check them if needed.`,
+	MessageAssign: `
+{{- if .Assignees}}
+{{- if .Error}}
+this {{if .IsPullRequest}}pull request{{else}}issue{{end}} can not be assigned to: {{.Assignees}}, because gitee returns the error: {{.Error}}
+{{- else}}
+this {{if .IsPullRequest}}pull request{{else}}issue{{end}} is assigned to: {{.Assignees}}.
+{{- end}}
+{{- end}}
+{{- if .AlreadyAssigned}}
+this {{if .IsPullRequest}}pull request{{else}}issue{{end}} is already assigned to: {{.AlreadyAssigned}}. please do not assign repeatedly.
+{{- end}}
+{{- if .NotCollaborators}}
+this {{if .IsPullRequest}}pull request{{else}}issue{{end}} can not be assigned to: {{.NotCollaborators}}, because they are not collaborators of this repository.
+please try to assign to the repository collaborators.
+{{- end}}`,
+	MessageUnassign: `
+{{- if .Unassignees}}
+{{- if .Error}}
+{{.Unassignees}} can not be unassigned from this {{if .IsPullRequest}}pull request{{else}}issue{{end}}, because gitee returns the error: {{.Error}}
+{{- else}}
+{{.Unassignees}} unassigned from this {{if .IsPullRequest}}pull request{{else}}issue{{end}}.
+{{- end}}
+{{- end}}
+{{- if .NotAssigned}}
+{{.NotAssigned}} can not be unassigned from this {{if .IsPullRequest}}pull request{{else}}issue{{end}}, because they are not assigned.
+please try to unassign the assignees from this {{if .IsPullRequest}}pull request{{else}}issue{{end}}.
+{{- end}}`,
+	MessageCC: `
+{{- if .Requested}}
+{{.Requested}} requested to review this pull request by: ***@{{.Login}}***. :wave: 
+{{- end}}
+{{- if .AlreadyRequested}}
+{{.AlreadyRequested}} already requested to review this pull request.
+{{- end}}
+{{- if .NotCollaborators}}
+{{.NotCollaborators}} can not be requested to review this pull request. :astonished:
+please try to request the repository collaborators.
+{{- end}}`,
+	MessageUncc: `
+{{- if .Removed}}
+{{.Removed}} removed from the reviewers of this pull request by: ***@{{.Login}}***.
+{{- end}}
+{{- if .NotRequested}}
+{{.NotRequested}} not requested to review this pull request.
+{{- end}}`,
+	MessageBlunderbuss: `{{.Reviewers}}, you are picked to review this pull request according to the OWNERS files. :wave:
+please comment ***/lgtm*** if it looks good to you.`,
+	MessageReviewReminder: `{{.Reviewers}}, this pull request has been waiting for your review for more than {{.Hours}} hours. :alarm_clock:
+please take a look, or comment ***/uncc*** if you are not able to review it.`,
+	MessageReviewEscalation: `{{.Approvers}}, this pull request has been waiting for the review of {{.Reviewers}} for more than {{.Hours}} hours. :rotating_light:
+please help to review it or find other reviewers.`,
+	MessageCloseIssue:  `this issue is closed by: ***@{{.Login}}***.`,
+	MessageReopenIssue: `this issue is reopened by: ***@{{.Login}}***.`,
+}
+
+// builtinMessageTemplates are used if no template is loaded
+var builtinMessageTemplates = newMessageTemplates()
+
+// MessageData is the data of message template
+// the common fields, e.g. CommunityName and BotName, are filled by RenderMessage
+type MessageData map[string]interface{}
+
+// MessageTemplates holds the templates of bot messages
+// the key of template is the path without extension in templates directory:
+// <locale>/<name> for community, <owner>/<locale>/<name> for organization
+// and <owner>/<repo>/<locale>/<name> for repository
+type MessageTemplates struct {
+	templates map[string]*template.Template
+}
+
+// newMessageTemplates returns the message templates with built-in templates
+func newMessageTemplates() *MessageTemplates {
+	mt := &MessageTemplates{
+		templates: map[string]*template.Template{},
+	}
+	for name, text := range defaultMessageTemplates {
+		key := path.Join(defaultLocale, name)
+		mt.templates[key] = template.Must(parseMessageTemplate(key, text))
+	}
+	return mt
+}
+
+// parseMessageTemplate parses the template, the missing fields are reported as errors
+func parseMessageTemplate(key, text string) (*template.Template, error) {
+	return template.New(key).Option("missingkey=error").Parse(text)
+}
+
+// messageSampleData are the sample data of the fields which are only used in some messages
+var messageSampleData = map[string]MessageData{
+	MessageClaNotFound: {
+		"UnsignedCommits": []CLAUnsignedCommit{{Sha: "abcdef1", Emails: "user@example.com"}},
+	},
+	MessageOnboarding: {
+		"IsPullRequest":    true,
+		"ClaSigned":        false,
+		"ClaUnknown":       false,
+		"ContributingLink": "https://example.com/contributing",
+	},
+	MessageHoldAdded:              {"Label": LabelNameHold},
+	MessageHoldRemoved:            {"Label": LabelNameHold},
+	MessageHoldAddNoPermission:    {"Label": LabelNameHold},
+	MessageHoldRemoveNoPermission: {"Label": LabelNameHold},
+	MessageMergeBlocked: {
+		"Blockers": []MergeBlocker{{Type: mergeBlockerTypeMissingLabel, Label: LabelNameLgtm}},
+	},
+	MessageMergeFailed:        {"Error": "error"},
+	MessageMergeMethodSet:     {"Method": MergeMethodSquash},
+	MessageMergeMethodInvalid: {"Method": "method"},
+	MessageNeedsRebase:        {"Label": LabelNameRebase},
+	MessageLifecycleStale: {
+		"IsPullRequest": true,
+		"Days":          90,
+		"Label":         LabelNameLifecycleStale,
+		"RottenLabel":   LabelNameLifecycleRotten,
+		"RottenDays":    30,
+	},
+	MessageLifecycleRotten: {
+		"IsPullRequest": true,
+		"Days":          30,
+		"Label":         LabelNameLifecycleRotten,
+		"CloseDays":     30,
+	},
+	MessageLifecycleClose:   {"IsPullRequest": true, "Days": 30},
+	MessageLifecycleAdded:   {"IsPullRequest": true, "Label": LabelNameLifecycleFrozen},
+	MessageLifecycleRemoved: {"IsPullRequest": true, "Label": LabelNameLifecycleFrozen},
+	MessageLabelNotAllowed:  {"Labels": "***kind/bug***", "AllowedLabels": "***kind/feature***"},
+	MessageLabelNoAllowlist: {"Labels": "***kind/bug***"},
+	MessageUnknownLabels:    {"Labels": "***kind/bug***", "ValidLabels": []string{"kind/feature"}},
+	MessageNoValidLabels:    {"Labels": "***kind/bug***"},
+	MessageRateLimitUser:    {"Until": "2006-01-02 15:04:05 MST"},
+	MessageRateLimitRepo:    {"Until": "2006-01-02 15:04:05 MST"},
+	MessageCommitCheckFailed: {
+		"Failures": []CommitCheckFailure{{Type: commitCheckFailureTypeDCO, Sha: "abcdef1", Name: "name", Email: "user@example.com"}},
+	},
+	MessageAssign: {
+		"IsPullRequest":    true,
+		"Assignees":        "***@login***",
+		"AlreadyAssigned":  "",
+		"NotCollaborators": "",
+		"Error":            "",
+	},
+	MessageUnassign: {
+		"IsPullRequest": true,
+		"Unassignees":   "***@login***",
+		"NotAssigned":   "",
+		"Error":         "",
+	},
+	MessageCC: {
+		"Requested":        "***@login***",
+		"AlreadyRequested": "",
+		"NotCollaborators": "",
+	},
+	MessageUncc: {
+		"Removed":      "***@login***",
+		"NotRequested": "",
+	},
+	MessageBlunderbuss:      {"Reviewers": "***@login***"},
+	MessageReviewReminder:   {"Reviewers": "***@login***", "Hours": 48},
+	MessageReviewEscalation: {"Approvers": "***@login***", "Reviewers": "***@login***", "Hours": 48},
+}
+
+// getSampleMessageData returns the data which contains all the fields of message
+func getSampleMessageData(name string) MessageData {
+	data := MessageData{
+		"Login":         "login",
+		"Owner":         "owner",
+		"Repo":          "repo",
+		"CommunityName": "community",
+		"BotName":       "bot",
+		"ClaLink":       "https://example.com/cla",
+		"CommandLink":   "https://example.com/command",
+		"ContactEmail":  "contact@example.com",
+	}
+	for k, v := range messageSampleData[name] {
+		data[k] = v
+	}
+	return data
+}
+
+// LoadMessageTemplates loads the template files in directory over the built-in templates
+// all the templates are validated, so the broken templates are found at startup
+func LoadMessageTemplates(dir string) (*MessageTemplates, error) {
+	mt := newMessageTemplates()
+	if dir == "" {
+		return mt, nil
+	}
+	// the built-in templates are used if the directory is not deployed
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		glog.Warningf("message templates directory is not found, the built-in templates are used: %s", dir)
+		return mt, nil
+	}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(p) != templateFileExt {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(filepath.ToSlash(rel), templateFileExt)
+		name := path.Base(key)
+		if _, ok := defaultMessageTemplates[name]; !ok {
+			return fmt.Errorf("unknown message template %s in %s", name, p)
+		}
+		if !strings.Contains(key, "/") {
+			return fmt.Errorf("message template %s is not in a locale directory", p)
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		// the last line ending of file is not a part of message
+		t, err := parseMessageTemplate(key, strings.TrimRight(string(content), "\r\n"))
+		if err != nil {
+			return fmt.Errorf("invalid message template %s: %v", p, err)
+		}
+		err = t.Execute(&bytes.Buffer{}, getSampleMessageData(name))
+		if err != nil {
+			return fmt.Errorf("invalid message template %s: %v", p, err)
+		}
+		mt.templates[key] = t
+		glog.Infof("loaded message template: %s", key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mt, nil
+}
+
+// lookup returns the template of name in locale, the template of repository is preferred to
+// the template of organization, and the template of organization is preferred to the template of community
+func (mt *MessageTemplates) lookup(owner, repo, locale, name string) *template.Template {
+	keys := []string{
+		path.Join(owner, repo, locale, name),
+		path.Join(owner, locale, name),
+		path.Join(locale, name),
+	}
+	for _, k := range keys {
+		if t, ok := mt.templates[k]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// Render renders the message of name in each locale and joins them
+// the locales without template are skipped, and the built-in template is used if none is found
+// the leading and trailing line endings are trimmed, so the optional parts of message can start new lines
+func (mt *MessageTemplates) Render(owner, repo string, locales []string, name string, data MessageData) (string, error) {
+	listOfMessages := make([]string, 0)
+	for _, locale := range locales {
+		t := mt.lookup(owner, repo, locale, name)
+		if t == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		err := t.Execute(&buf, data)
+		if err != nil {
+			return "", err
+		}
+		listOfMessages = append(listOfMessages, strings.Trim(buf.String(), "\n"))
+	}
+	if len(listOfMessages) > 0 {
+		return strings.Join(listOfMessages, localeSeparator), nil
+	}
+
+	t := mt.lookup(owner, repo, defaultLocale, name)
+	if t == nil {
+		return "", fmt.Errorf("message template %s is not found", name)
+	}
+	var buf bytes.Buffer
+	err := t.Execute(&buf, data)
+	return strings.Trim(buf.String(), "\n"), err
+}
+
+// GetLocales returns the locales of messages for the user in repository
+// the locale of user is preferred to the locales of repository and organization
+func (s *Server) GetLocales(owner, repo, login string) []string {
+	cfg := s.Config.Templates
+	if locale, ok := cfg.UserLocales[login]; ok && locale != "" {
+		return []string{locale}
+	}
+	if locales, ok := cfg.RepoLocales[fmt.Sprintf("%s/%s", owner, repo)]; ok && len(locales) > 0 {
+		return locales
+	}
+	if locales, ok := cfg.RepoLocales[owner]; ok && len(locales) > 0 {
+		return locales
+	}
+	if len(cfg.Locales) > 0 {
+		return cfg.Locales
+	}
+	return []string{defaultLocale}
+}
+
+// RenderMessage renders the message of name for the user in repository
+// the user is the receiver of message, whose locale is preferred
+func (s *Server) RenderMessage(owner, repo, login, name string, data MessageData) string {
+	mt := s.Templates
+	if mt == nil {
+		mt = builtinMessageTemplates
+	}
+
+	// fill the common fields
+	fullData := MessageData{
+		"Login":         login,
+		"Owner":         owner,
+		"Repo":          repo,
+		"CommunityName": s.Config.CommunityName,
+		"BotName":       s.Config.BotName,
+		"ClaLink":       s.Config.ClaLink,
+		"CommandLink":   s.Config.CommandLink,
+		"ContactEmail":  s.Config.ContactEmail,
+	}
+	for k, v := range data {
+		fullData[k] = v
+	}
+
+	message, err := mt.Render(owner, repo, s.GetLocales(owner, repo, login), name, fullData)
+	if err != nil {
+		glog.Errorf("unable to render message %s: %v", name, err)
+		// fall back to the built-in template
+		message, err = builtinMessageTemplates.Render(owner, repo, []string{defaultLocale}, name, fullData)
+		if err != nil {
+			glog.Errorf("unable to render built-in message %s: %v", name, err)
+		}
+	}
+	return message
+}
@@ -1,93 +1,131 @@
 package cibot
 
 import (
-	"fmt"
-	"strings"
-
 	"gitee.com/openeuler/go-gitee/gitee"
 	"github.com/golang/glog"
 )
 
-const (
-	issueUnAssignMessage       = `***@%s*** is unassigned from this issue.`
-	issueCanNotUnAssignMessage = `***@%s*** can not be unassigned from this issue.
-please try to unassign the assignee from this issue.`
-)
-
-// UnAssign a collaborator for issue
+// UnAssign collaborators for issue or pull request
 func (s *Server) UnAssign(event *gitee.NoteEvent) error {
 	if *event.NoteableType == "Issue" {
 		// handle open
 		if event.Issue.State == "open" {
-			// get basic informations
-			comment := event.Comment.Body
-			owner := event.Repository.Namespace
-			repo := event.Repository.Name
-			issueNumber := event.Issue.Number
-			issueAuthor := event.Issue.User.Login
-			commentAuthor := event.Comment.User.Login
-			glog.Infof("unassign started. comment: %s owner: %s repo: %s issueNumber: %s issueAuthor: %s commentAuthor: %s",
-				comment, owner, repo, issueNumber, issueAuthor, commentAuthor)
+			return s.UnAssignIssue(event)
+		}
+	} else if *event.NoteableType == "PullRequest" {
+		// handle open
+		if event.PullRequest.State == "open" {
+			return s.UnAssignPullRequest(event)
+		}
+	}
+	return nil
+}
 
-			// split the assignees and operation to be performed.
-			substrings := strings.Split(comment, "@")
-			// default is comment author
-			unassignee := commentAuthor
-			if len(substrings) > 1 {
-				unassignee = strings.TrimSpace(substrings[1])
-			}
+// UnAssignIssue removes the assignee or collaborators of issue
+func (s *Server) UnAssignIssue(event *gitee.NoteEvent) error {
+	// get basic informations
+	comment := event.Comment.Body
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	issueNumber := event.Issue.Number
+	issueAuthor := event.Issue.User.Login
+	commentAuthor := event.Comment.User.Login
+	glog.Infof("unassign started. comment: %s owner: %s repo: %s issueNumber: %s issueAuthor: %s commentAuthor: %s",
+		comment, owner, repo, issueNumber, issueAuthor, commentAuthor)
 
-			// if issue assignee is the same with unassignee
-			issueAssignee := ""
-			if event.Issue.Assignee != nil {
-				issueAssignee = event.Issue.Assignee.Login
-			}
-			if issueAssignee == unassignee {
-				body := gitee.IssueUpdateParam{}
-				body.Repo = repo
-				// set " " to empty the assignee by gitee
-				body.Assignee = " "
-				body.AccessToken = s.Config.GiteeToken
-				// build label string
-				var strLabel string
-				for _, l := range event.Issue.Labels {
-					strLabel += l.Name + ","
-				}
-				strLabel = strings.TrimRight(strLabel, ",")
-				if strLabel == "" {
-					strLabel = ","
-				}
-				body.Labels = strLabel
-				glog.Infof("invoke api to unassign: %s", issueNumber)
+	// current assignee and collaborators
+	issueAssignee := ""
+	if event.Issue.Assignee != nil {
+		issueAssignee = event.Issue.Assignee.Login
+	}
+	mapOfCollaborators := map[string]bool{}
+	for _, c := range event.Issue.Collaborators {
+		mapOfCollaborators[c.Login] = true
+	}
 
-				// patch assignee
-				_, _, err := s.GiteeClient.IssuesApi.PatchV5ReposOwnerIssuesNumber(s.Context, owner, issueNumber, body)
-				if err != nil {
-					glog.Errorf("unable to unassign: %s err: %v", issueNumber, err)
-					return err
-				}
-				glog.Infof("unassign successfully: %v", issueNumber)
+	listOfUnAssignees := make([]string, 0)
+	listOfNotAssigned := make([]string, 0)
+	for _, login := range GetLoginsFromComment(RegUnAssign, comment, commentAuthor) {
+		if login == issueAssignee {
+			issueAssignee = ""
+		} else if mapOfCollaborators[login] {
+			delete(mapOfCollaborators, login)
+		} else {
+			listOfNotAssigned = append(listOfNotAssigned, login)
+			continue
+		}
+		listOfUnAssignees = append(listOfUnAssignees, login)
+	}
 
-				// add comment
-				bodyComment := gitee.IssueCommentPostParam{}
-				bodyComment.AccessToken = s.Config.GiteeToken
-				bodyComment.Body = fmt.Sprintf(issueUnAssignMessage, unassignee)
-				_, _, err = s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, issueNumber, bodyComment)
-				if err != nil {
-					glog.Errorf("unable to add comment in issue: %v", err)
-				}
-			} else {
-				glog.Infof("can not unassign: %v", issueNumber)
-				// add comment
-				body := gitee.IssueCommentPostParam{}
-				body.AccessToken = s.Config.GiteeToken
-				body.Body = fmt.Sprintf(issueCanNotUnAssignMessage, unassignee)
-				_, _, err := s.GiteeClient.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(s.Context, owner, repo, issueNumber, body)
-				if err != nil {
-					glog.Errorf("unable to add comment in issue: %v", err)
-				}
+	// patch assignee and collaborators
+	var patchErr error
+	if len(listOfUnAssignees) > 0 {
+		// keep the order of collaborators
+		listOfCollaborators := make([]string, 0, len(mapOfCollaborators))
+		for _, c := range event.Issue.Collaborators {
+			if mapOfCollaborators[c.Login] {
+				listOfCollaborators = append(listOfCollaborators, c.Login)
 			}
 		}
+		glog.Infof("invoke api to unassign: %s assignee: %s collaborators: %v", issueNumber, issueAssignee, listOfCollaborators)
+		patchErr = s.PatchIssueAssignees(event, issueAssignee, listOfCollaborators)
 	}
-	return nil
+
+	// add comment
+	return s.AddCommentInNoteEvent(event, s.buildUnAssignMessage(event, listOfUnAssignees, listOfNotAssigned, patchErr))
+}
+
+// UnAssignPullRequest removes the assignees of pull request
+func (s *Server) UnAssignPullRequest(event *gitee.NoteEvent) error {
+	// get basic params
+	comment := event.Comment.Body
+	owner := event.Repository.Namespace
+	repo := event.Repository.Name
+	prAuthor := event.PullRequest.User.Login
+	prNumber := event.PullRequest.Number
+	commentAuthor := event.Comment.User.Login
+	glog.Infof("unassign started. comment: %s prAuthor: %s commentAuthor: %s owner: %s repo: %s number: %d",
+		comment, prAuthor, commentAuthor, owner, repo, prNumber)
+
+	// current assignees
+	mapOfAssignees := map[string]bool{}
+	for _, assignee := range event.PullRequest.Assignees {
+		mapOfAssignees[assignee.Login] = true
+	}
+
+	listOfUnAssignees := make([]string, 0)
+	listOfNotAssigned := make([]string, 0)
+	for _, login := range GetLoginsFromComment(RegUnAssign, comment, commentAuthor) {
+		if mapOfAssignees[login] {
+			listOfUnAssignees = append(listOfUnAssignees, login)
+		} else {
+			listOfNotAssigned = append(listOfNotAssigned, login)
+		}
+	}
+
+	// remove assignees
+	var removeErr error
+	if len(listOfUnAssignees) > 0 {
+		removeErr = s.RemoveSpecifyAssigneesInPullRequest(owner, repo, prNumber, listOfUnAssignees)
+		if removeErr == nil {
+			removeErr = s.RemoveReviewRequests(owner, repo, prNumber, listOfUnAssignees)
+		}
+	}
+
+	// add comment
+	return s.AddCommentInNoteEvent(event, s.buildUnAssignMessage(event, listOfUnAssignees, listOfNotAssigned, removeErr))
+}
+
+// buildUnAssignMessage builds one comment for the result of unassign
+func (s *Server) buildUnAssignMessage(event *gitee.NoteEvent, listOfUnAssignees, listOfNotAssigned []string, err error) string {
+	errMessage := ""
+	if err != nil {
+		errMessage = GetGiteeErrorMessage(err)
+	}
+	return s.RenderMessage(event.Repository.Namespace, event.Repository.Name, event.Comment.User.Login, MessageUnassign, MessageData{
+		"IsPullRequest": *event.NoteableType == "PullRequest",
+		"Unassignees":   GetMentions(listOfUnAssignees),
+		"NotAssigned":   GetMentions(listOfNotAssigned),
+		"Error":         errMessage,
+	})
 }
@@ -9,26 +9,24 @@ import (
 const (
 	kind              = "/kind"
 	RemoveKind        = "/remove-kind"
-	AddClaYes         = "/%s-cla yes"
-	AddClaNo          = "/%s-cla no"
-	RemoveClaYes      = "/remove-%s-cla yes"
-	RemoveClaNo       = "/remove-%s-cla no"
 	LabelNameLgtm     = "lgtm"
 	LabelNameApproved = "approved"
+	LabelNameHold     = "do-not-merge/hold"
+	LabelNameRebase   = "needs-rebase"
 	LabelHiddenValue  = "<input type=hidden value=%s />"
-	tipBotMessage     = `Hey ***@%s***, Welcome to %s Community.
-All of the projects in %s Community are maintained by ***@%s***.
-That means the developpers can comment below every pull request or issue to trigger Bot Commands.
-Please follow instructions at <%s> to find the details.`
+	// ClaCheckedShaHiddenValue is the hidden head sha checked by the cla status comment
+	ClaCheckedShaHiddenValue = "<input type=hidden name=cla-sha value=%s />"
 )
 
 var (
-	// RegAddLabel
-	RegAddLabel = regexp.MustCompile(`(?mi)^/(kind|priority|sig)\s*(.*)$`)
+	// RegLabel
+	RegLabel = regexp.MustCompile(`(?mi)^/label[ \t]+(.*?)\s*$`)
 	// RegRemoveLabel
-	RegRemoveLabel = regexp.MustCompile(`(?mi)^/remove-(kind|priority|sig)\s*(.*)$`)
+	RegRemoveLabel = regexp.MustCompile(`(?mi)^/remove-label[ \t]+(.*?)\s*$`)
 	// RegCheckCLA
 	RegCheckCLA = regexp.MustCompile(`(?mi)^/check-cla\s*$`)
+	// RegCheckDCO
+	RegCheckDCO = regexp.MustCompile(`(?mi)^/check-dco\s*$`)
 	// RegAddLgtm
 	RegAddLgtm = regexp.MustCompile(`(?mi)^/lgtm\s*$`)
 	// RegRemoveLgtm
@@ -37,16 +35,38 @@ var (
 	RegAddApprove = regexp.MustCompile(`(?mi)^/approve\s*$`)
 	// RegRemoveApprove
 	RegRemoveApprove = regexp.MustCompile(`(?mi)^/approve cancel\s*$`)
+	// RegHold
+	RegHold = regexp.MustCompile(`(?mi)^/hold\s*$`)
+	// RegHoldCancel
+	RegHoldCancel = regexp.MustCompile(`(?mi)^/hold cancel\s*$`)
+	// RegMergeMethod
+	RegMergeMethod = regexp.MustCompile(`(?mi)^/merge-method[ \t]*(.*?)[ \t]*$`)
 	// RegClose
 	RegClose = regexp.MustCompile(`(?mi)^/close\s*$`)
 	// RegReOpen
 	RegReOpen = regexp.MustCompile(`(?mi)^/reopen\s*$`)
 	// RegBotAddLgtm
 	RegBotAddLgtm = regexp.MustCompile(fmt.Sprintf(LabelHiddenValue, "(.*)"))
+	// RegClaCheckedSha
+	RegClaCheckedSha = regexp.MustCompile(fmt.Sprintf(ClaCheckedShaHiddenValue, "(\\w+)"))
 	// RegAssign
 	RegAssign = regexp.MustCompile(`(?mi)^/assign(( @?[-\w]+?)*)\s*$`)
 	// RegUnAssign
 	RegUnAssign = regexp.MustCompile(`(?mi)^/unassign(( @?[-\w]+?)*)\s*$`)
+	// RegCC
+	RegCC = regexp.MustCompile(`(?mi)^/cc(( @?[-\w]+?)*)\s*$`)
+	// RegUnCC
+	RegUnCC = regexp.MustCompile(`(?mi)^/uncc(( @?[-\w]+?)*)\s*$`)
+	// RegLifecycle
+	RegLifecycle = regexp.MustCompile(`(?mi)^/lifecycle\s+(frozen|stale|rotten)\s*$`)
+	// RegRemoveLifecycle
+	RegRemoveLifecycle = regexp.MustCompile(`(?mi)^/remove-lifecycle\s+(frozen|stale|rotten)\s*$`)
+	// RegReleaseNote
+	RegReleaseNote = regexp.MustCompile(`(?mi)^/release-note([ \t]+.*?)?\s*$`)
+	// RegReleaseNoteNone
+	RegReleaseNoteNone = regexp.MustCompile(`(?mi)^/release-note-none\s*$`)
+	// RegReleaseNoteBlock
+	RegReleaseNoteBlock = regexp.MustCompile("(?s)```release-note[ \t]*\r?\n(.*?)```")
 )
 
 // UrlEncode replcae special chars in url
@@ -5,6 +5,7 @@ import (
 	goflag "flag"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strconv"
 
 	"gitee.com/openeuler/ci-bot/pkg/cibot/config"
@@ -56,6 +57,35 @@ func (s *Webhook) Run() {
 		glog.Fatalf("fail to unmarshal: %v", err)
 	}
 
+	// read label rules file
+	if config.LabelRulesFile != "" {
+		labelRules, err := config.LoadLabelRules()
+		if os.IsNotExist(err) {
+			// the file is not mounted, so only the label rules in config are used
+			glog.Warningf("label rules file is not found: %s", config.LabelRulesFile)
+		} else if err != nil {
+			glog.Fatalf("could not load label rules file: %v", err)
+		}
+		config.LabelRules = append(config.LabelRules, labelRules...)
+	}
+
+	// read label definitions file
+	if config.LabelDefinitionsFile != "" {
+		config.LabelDefinitions, err = config.LoadLabelDefinitions()
+		if os.IsNotExist(err) {
+			// the file is not mounted, so no label is defined
+			glog.Warningf("label definitions file is not found: %s", config.LabelDefinitionsFile)
+		} else if err != nil {
+			glog.Fatalf("could not load label definitions file: %v", err)
+		}
+	}
+
+	// load and validate message templates
+	messageTemplates, err := LoadMessageTemplates(config.Templates.Directory)
+	if err != nil {
+		glog.Fatalf("could not load message templates: %v", err)
+	}
+
 	// oauth
 	oauthSecret := config.GiteeToken
 	ctx := context.Background()
@@ -91,15 +121,76 @@ func (s *Webhook) Run() {
 		Config:      config,
 		Context:     ctx,
 		GiteeClient: giteeClient,
+		RateLimiter: NewRateLimiter(),
+		Templates:   messageTemplates,
 	}
 	http.HandleFunc("/webhook", webHookHandler.ServeHTTP)
 
+	// setting comment commands handler
+	commentCommandsHandler := &CommentCommandsHandler{
+		Server: webHookHandler,
+	}
+	go commentCommandsHandler.Serve()
+
+	// setting merge queue
+	mergeQueue := &MergeQueue{
+		Server: webHookHandler,
+	}
+	go mergeQueue.Serve()
+	http.HandleFunc("/merge-queue", mergeQueue.ServeHTTP)
+
+	// setting cla backfill handler
+	claBackfillHandler := &CLABackfillHandler{
+		Server: webHookHandler,
+	}
+	go claBackfillHandler.Serve()
+
+	// setting needs rebase handler
+	needsRebaseHandler := &NeedsRebaseHandler{
+		Server: webHookHandler,
+	}
+	go needsRebaseHandler.Serve()
+
+	// setting review reminder handler
+	reviewReminderHandler := &ReviewReminderHandler{
+		Server: webHookHandler,
+	}
+	go reviewReminderHandler.Serve()
+
+	// setting lifecycle handler
+	lifecycleHandler := &LifecycleHandler{
+		Server: webHookHandler,
+	}
+	go lifecycleHandler.Serve()
+
+	// setting label definitions handler
+	labelDefinitionsHandler := &LabelDefinitionsHandler{
+		Server: webHookHandler,
+	}
+	go labelDefinitionsHandler.Serve()
+
+	// setting release notes handler
+	releaseNotesHandler := &ReleaseNotesHandler{
+		Server: webHookHandler,
+	}
+	http.HandleFunc("/release-notes", releaseNotesHandler.ServeHTTP)
+
 	// setting cla handler
 	claHandler := CLAHandler{
-		Context: ctx,
+		Server: webHookHandler,
 	}
 	http.HandleFunc("/cla", claHandler.ServeHTTP)
 
+	// setting corporate cla handlers
+	corporationManagersHandler := &CorporationManagersHandler{
+		Server: webHookHandler,
+	}
+	http.HandleFunc("/cla/corporation-managers", corporationManagersHandler.ServeHTTP)
+	corporationMembersHandler := &CorporationMembersHandler{
+		Server: webHookHandler,
+	}
+	http.HandleFunc("/cla/corporation-members", corporationMembersHandler.ServeHTTP)
+
 	//starting server
 	address := s.Address + ":" + strconv.FormatInt(s.Port, 10)
 	if err := http.ListenAndServe(address, nil); err != nil {